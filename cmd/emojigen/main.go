@@ -0,0 +1,103 @@
+// emojigen membuat ulang assets/emoji dari bitmap Noto Color Emoji.
+//
+//	go run ./cmd/emojigen <NotoColorEmoji.ttf> <gomoji data.go> <dst> <size>
+//
+// assets/emoji dibuat dengan size 64 dari NotoColorEmoji.ttf (bitmap CBDT) di
+// github.com/go-text/typesetting-utils/opentype/bitmap dan daftar emoji dari data.go
+// milik github.com/forPelevin/gomoji v1.2.0 yang hanya dibaca sebagai teks.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/imaging"
+	otfont "github.com/go-text/typesetting/font"
+	"github.com/go-text/typesetting/language"
+	"github.com/go-text/typesetting/shaping"
+	"golang.org/x/image/math/fixed"
+)
+
+var codePointRe = regexp.MustCompile(`CodePoint:\s+"([0-9A-F ]+)"`)
+
+func main() {
+	if len(os.Args) != 5 {
+		log.Fatal("usage: emojigen <NotoColorEmoji.ttf> <gomoji data.go> <dst> <size>")
+	}
+	data, err := os.ReadFile(os.Args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+	face, err := otfont.ParseTTF(bytes.NewReader(data))
+	if err != nil {
+		log.Fatal(err)
+	}
+	list, err := os.ReadFile(os.Args[2])
+	if err != nil {
+		log.Fatal(err)
+	}
+	dst := os.Args[3]
+	size, err := strconv.Atoi(os.Args[4])
+	if err != nil || size <= 0 {
+		log.Fatalf("invalid size: %s", os.Args[4])
+	}
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		log.Fatal(err)
+	}
+
+	var shaper shaping.HarfbuzzShaper
+	seen := map[string]bool{}
+	n, total, miss := 0, 0, 0
+	for _, m := range codePointRe.FindAllStringSubmatch(string(list), -1) {
+		// nama file mengikuti Twemoji: codepoint hex huruf kecil tanpa FE0F
+		var seq []rune
+		var parts []string
+		for _, f := range strings.Fields(m[1]) {
+			v, _ := strconv.ParseUint(f, 16, 32)
+			seq = append(seq, rune(v))
+			if v != 0xfe0f {
+				parts = append(parts, strings.ToLower(strings.TrimLeft(f, "0")))
+			}
+		}
+		name := strings.Join(parts, "-")
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		// urutan yang tidak menjadi satu glyph (ligature ZWJ/skin tone) tidak didukung font
+		out := shaper.Shape(shaping.Input{Text: seq, RunEnd: len(seq), Face: face, Size: fixed.I(64), Script: language.Common})
+		if len(out.Glyphs) != 1 {
+			miss++
+			continue
+		}
+		bm, ok := face.GlyphData(out.Glyphs[0].GlyphID).(otfont.GlyphBitmap)
+		if !ok || bm.Format != otfont.PNG {
+			miss++
+			continue
+		}
+		img, err := png.Decode(bytes.NewReader(bm.Data))
+		if err != nil {
+			log.Fatalf("%s: %v", name, err)
+		}
+		res := quantizeRGBA(imaging.Resize(img, 0, size, imaging.Lanczos), 256)
+		var buf bytes.Buffer
+		enc := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := enc.Encode(&buf, res); err != nil {
+			log.Fatalf("%s: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dst, name+".png"), buf.Bytes(), 0644); err != nil {
+			log.Fatal(err)
+		}
+		n++
+		total += buf.Len()
+	}
+	fmt.Printf("%d emoji, %d bytes, %d skipped\n", n, total, miss)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"sort"
+)
+
+// quantizeRGBA mengurangi warna menjadi n entri palet dengan median cut pada RGBA
+// yang tidak premultiplied, indeks 0 dipakai untuk piksel transparan penuh
+func quantizeRGBA(img *image.NRGBA, n int) *image.Paletted {
+	b := img.Bounds()
+	type px [4]uint8
+	var pts []px
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := img.NRGBAAt(x, y)
+			if c.A == 0 {
+				continue
+			}
+			pts = append(pts, px{c.R, c.G, c.B, c.A})
+		}
+	}
+	boxes := [][]px{pts}
+	for len(boxes) < n-1 {
+		// pecah box dengan rentang kanal * jumlah piksel terbesar
+		best, bestScore, bestCh := -1, 0, 0
+		for i, bx := range boxes {
+			if len(bx) < 2 {
+				continue
+			}
+			for ch := 0; ch < 4; ch++ {
+				lo, hi := 255, 0
+				for _, p := range bx {
+					lo, hi = min(lo, int(p[ch])), max(hi, int(p[ch]))
+				}
+				score := (hi - lo) * len(bx)
+				if score > bestScore {
+					best, bestScore, bestCh = i, score, ch
+				}
+			}
+		}
+		if best < 0 {
+			break
+		}
+		bx := boxes[best]
+		ch := bestCh
+		sort.Slice(bx, func(i, j int) bool { return bx[i][ch] < bx[j][ch] })
+		m := len(bx) / 2
+		boxes[best] = bx[:m]
+		boxes = append(boxes, bx[m:])
+	}
+	pal := color.Palette{color.NRGBA{}}
+	for _, bx := range boxes {
+		if len(bx) == 0 {
+			continue
+		}
+		var s [4]int
+		for _, p := range bx {
+			for ch := range 4 {
+				s[ch] += int(p[ch])
+			}
+		}
+		k := len(bx)
+		pal = append(pal, color.NRGBA{uint8(s[0] / k), uint8(s[1] / k), uint8(s[2] / k), uint8(s[3] / k)})
+	}
+	dst := image.NewPaletted(b, pal)
+	cache := map[px]uint8{}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := img.NRGBAAt(x, y)
+			if c.A == 0 {
+				dst.SetColorIndex(x, y, 0)
+				continue
+			}
+			k := px{c.R, c.G, c.B, c.A}
+			idx, ok := cache[k]
+			if !ok {
+				bestD := 1 << 62
+				for i, pc := range pal {
+					q := pc.(color.NRGBA)
+					// selisih warna diberi bobot alpha supaya tepi transparan tidak terlalu menentukan
+					a := int(c.A)
+					dr, dg, db, da := int(q.R)-int(c.R), int(q.G)-int(c.G), int(q.B)-int(c.B), int(q.A)-int(c.A)
+					d := (dr*dr+dg*dg+db*db)*a/255 + 2*da*da
+					if d < bestD {
+						bestD, idx = d, uint8(i)
+					}
+				}
+				cache[k] = idx
+			}
+			dst.SetColorIndex(x, y, idx)
+		}
+	}
+	return dst
+}
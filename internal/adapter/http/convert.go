@@ -0,0 +1,140 @@
+package http
+
+import (
+	"MemeCraft/internal/adapter/http/dto"
+	"MemeCraft/internal/preset"
+	"MemeCraft/internal/service/imageutil"
+	"MemeCraft/internal/service/meme"
+	"fmt"
+)
+
+const maxFiltersPerSlot = 10
+
+// maxTextBytes batas teks mentah per text box, jauh di atas max_chars preset mana pun termasuk markup
+const maxTextBytes = 4096
+
+// convertFilters memvalidasi filter per slot dari request sebelum gambar diunduh
+func convertFilters(p *preset.PresetSummary, payload map[string][]dto.Filter) (map[string][]imageutil.Filter, error) {
+	filters := make(map[string][]imageutil.Filter, len(payload))
+	for slot, list := range payload {
+		if !hasOverlaySlot(p, slot) {
+			return nil, fmt.Errorf("unknown overlay slot: %s", slot)
+		}
+
+		if len(list) > maxFiltersPerSlot {
+			return nil, fmt.Errorf("overlay %s: too many filters (max %d)", slot, maxFiltersPerSlot)
+		}
+
+		converted := make([]imageutil.Filter, 0, len(list))
+		for _, f := range list {
+			filter := imageutil.Filter{
+				Type:   imageutil.FilterType(f.Type),
+				Amount: f.Amount,
+				Color:  f.Color,
+				To:     f.To,
+				Angle:  f.Angle,
+				Blend:  f.Blend,
+			}
+			if err := filter.Validate(); err != nil {
+				return nil, fmt.Errorf("overlay %s: %w", slot, err)
+			}
+			converted = append(converted, filter)
+		}
+		filters[slot] = converted
+	}
+	return filters, nil
+}
+
+// convertCropHints memvalidasi titik fokus dan crop manual per slot dari request
+func convertCropHints(p *preset.PresetSummary, focusPayload map[string]dto.Point, cropPayload map[string]dto.Rect) (map[string]imageutil.FocalPoint, map[string]imageutil.CropRect, error) {
+	focus := make(map[string]imageutil.FocalPoint, len(focusPayload))
+	for slot, f := range focusPayload {
+		if !hasOverlaySlot(p, slot) {
+			return nil, nil, fmt.Errorf("unknown overlay slot: %s", slot)
+		}
+		point := imageutil.FocalPoint{X: f.X, Y: f.Y}
+		if err := point.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("overlay %s: %w", slot, err)
+		}
+		focus[slot] = point
+	}
+
+	crop := make(map[string]imageutil.CropRect, len(cropPayload))
+	for slot, c := range cropPayload {
+		if !hasOverlaySlot(p, slot) {
+			return nil, nil, fmt.Errorf("unknown overlay slot: %s", slot)
+		}
+		rect := imageutil.CropRect{X: c.X, Y: c.Y, Width: c.Width, Height: c.Height}
+		if err := rect.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("overlay %s: %w", slot, err)
+		}
+		crop[slot] = rect
+	}
+
+	return focus, crop, nil
+}
+
+// convertBackgrounds memvalidasi background mode contain per slot dari request
+func convertBackgrounds(p *preset.PresetSummary, payload map[string]dto.Background) (map[string]imageutil.Background, error) {
+	backgrounds := make(map[string]imageutil.Background, len(payload))
+	for slot, b := range payload {
+		if !hasOverlaySlot(p, slot) {
+			return nil, fmt.Errorf("unknown overlay slot: %s", slot)
+		}
+		bg := imageutil.Background{
+			Type:  imageutil.BackgroundType(b.Type),
+			Color: b.Color,
+			Blur:  b.Blur,
+		}
+		if err := bg.Validate(); err != nil {
+			return nil, fmt.Errorf("overlay %s: %w", slot, err)
+		}
+		backgrounds[slot] = bg
+	}
+	return backgrounds, nil
+}
+
+// validateText memeriksa markup teks user (mis. warna yang tidak valid) sebelum gambar diunduh
+func validateText(text map[string]string) error {
+	for name, value := range text {
+		if len(value) > maxTextBytes {
+			return fmt.Errorf("text %s: longer than %d bytes", name, maxTextBytes)
+		}
+		if _, err := imageutil.ParseMarkup(value); err != nil {
+			return fmt.Errorf("text %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// convertStyles mengubah override style dari request, batasnya diperiksa oleh Generator
+func convertStyles(payload map[string]dto.TextStyle) map[string]meme.TextStyle {
+	styles := make(map[string]meme.TextStyle, len(payload))
+	for name, s := range payload {
+		styles[name] = meme.TextStyle{
+			Color:     s.Color,
+			Size:      s.Size,
+			Align:     s.Align,
+			Normalize: s.Normalize,
+		}
+	}
+	return styles
+}
+
+// convertShapes mengubah override warna shape dari request, batasnya diperiksa oleh Generator
+func convertShapes(payload map[string]dto.ShapeStyle) map[string]meme.ShapeStyle {
+	shapes := make(map[string]meme.ShapeStyle, len(payload))
+	for name, s := range payload {
+		shapes[name] = meme.ShapeStyle{Color: s.Color, Stroke: s.Stroke}
+	}
+	return shapes
+}
+
+func hasOverlaySlot(p *preset.PresetSummary, name string) bool {
+	for _, o := range p.Overlays {
+		if o.Name == name {
+			return true
+		}
+	}
+	return false
+}
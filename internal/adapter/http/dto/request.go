@@ -1,7 +1,56 @@
 package dto
 
 type CreateMemeRequest struct {
-	Overlay    string            `json:"overlay"`
-	ResizeMode string            `json:"resize_mode"`
-	Text       map[string]string `json:"text"`
+	Overlay    string                `json:"overlay"`  // url untuk slot pertama
+	Overlays   map[string]string     `json:"overlays"` // key = nama slot, value = url
+	ResizeMode string                `json:"resize_mode"`
+	Filters    map[string][]Filter   `json:"filters"`    // key = nama slot, menggantikan filter preset
+	Focus      map[string]Point      `json:"focus"`      // key = nama slot, titik fokus 0..1
+	Crop       map[string]Rect       `json:"crop"`       // key = nama slot, area crop 0..1
+	Background map[string]Background `json:"background"` // key = nama slot, background mode contain
+	Text       map[string]string     `json:"text"`
+	Colors     map[string]string     `json:"colors"`   // key = nama text box, bentuk singkat style.color
+	Style      map[string]TextStyle  `json:"style"`    // key = nama text box, dibatasi overridable preset
+	Variants   map[string]string     `json:"variants"` // key = nama variant, mis. {"brand": "kompas"}
+	Codes      map[string]string     `json:"codes"`    // key = ref layer code, isi QR code/barcode
+	Shapes     map[string]ShapeStyle `json:"shapes"`   // key = nama layer shape, dibatasi overridable preset
+}
+
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type Rect struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+type Background struct {
+	Type  string  `json:"type"`
+	Color string  `json:"color"`
+	Blur  float64 `json:"blur"`
+}
+
+type Filter struct {
+	Type   string   `json:"type"`
+	Amount *float64 `json:"amount"` // kosong = default filter
+	Color  string   `json:"color"`
+	To     string   `json:"to"`
+	Angle  float64  `json:"angle"`
+	Blend  string   `json:"blend"`
+}
+
+type TextStyle struct {
+	Color     string  `json:"color"`
+	Size      float64 `json:"size"`
+	Align     string  `json:"align"`
+	Normalize string  `json:"normalize"`
+}
+
+type ShapeStyle struct {
+	Color  string `json:"color"`
+	Stroke string `json:"stroke"`
 }
@@ -3,10 +3,13 @@ package http
 import (
 	"MemeCraft/internal/adapter/http/dto"
 	"MemeCraft/internal/port"
+	"MemeCraft/internal/service/imageutil"
 	"MemeCraft/internal/service/meme"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"io"
 	"path/filepath"
 	"strings"
 )
@@ -14,6 +17,14 @@ import (
 type Handler struct {
 	memeGenerator   *meme.Generator
 	storageProvider port.StorageProvider
+	limits          Limits
+}
+
+// Limits batas dimensi gambar sebelum decode, per route
+type Limits struct {
+	Upload    imageutil.DecodeLimits    // POST /upload
+	Overlay   imageutil.DecodeLimits    // overlay pada POST /presets/:preset_id/memes
+	Animation imageutil.AnimationLimits // jumlah frame, durasi dan total pixel GIF, berlaku di kedua route
 }
 
 func (h *Handler) GenerateMeme(c *fiber.Ctx) error {
@@ -24,7 +35,7 @@ func (h *Handler) GenerateMeme(c *fiber.Ctx) error {
 		})
 	}
 
-	_, err := h.memeGenerator.GetPresetById(presetId)
+	p, err := h.memeGenerator.GetPresetById(presetId)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"message": err.Error(),
@@ -38,22 +49,83 @@ func (h *Handler) GenerateMeme(c *fiber.Ctx) error {
 		})
 	}
 
-	imageOverlay, err := DownloadImageAsBytes(payload.Overlay)
+	filters, err := convertFilters(p, payload.Filters)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	focus, crop, err := convertCropHints(p, payload.Focus, payload.Crop)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	backgrounds, err := convertBackgrounds(p, payload.Background)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"message": err.Error(),
 		})
 	}
 
+	if err := validateText(payload.Text); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	var imageOverlay []byte
+	if payload.Overlay != "" {
+		imageOverlay, err = h.downloadOverlay(payload.Overlay)
+		if err != nil {
+			return c.Status(imageErrorStatus(err)).JSON(fiber.Map{
+				"message": err.Error(),
+			})
+		}
+	}
+
+	imageOverlays := make(map[string][]byte, len(payload.Overlays))
+	for slot, url := range payload.Overlays {
+		if !hasOverlaySlot(p, slot) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "unknown overlay slot: " + slot,
+			})
+		}
+		data, err := h.downloadOverlay(url)
+		if err != nil {
+			return c.Status(imageErrorStatus(err)).JSON(fiber.Map{
+				"message": fmt.Sprintf("overlay %s: %v", slot, err),
+			})
+		}
+		imageOverlays[slot] = data
+	}
+
 	result, err := h.memeGenerator.Generate(&meme.Config{
 		PresetId:   presetId,
 		ResizeMode: payload.ResizeMode,
 		Overlay:    imageOverlay,
+		Overlays:   imageOverlays,
+		Filters:    filters,
+		Focus:      focus,
+		Crop:       crop,
+		Background: backgrounds,
 		Text:       payload.Text,
+		Colors:     payload.Colors,
+		Styles:     convertStyles(payload.Style),
+		Variants:   payload.Variants,
+		APIKey:     c.Get("X-API-Key"),
+		Codes:      payload.Codes,
+		Shapes:     convertShapes(payload.Shapes),
+
+		DecodeLimits:    h.limits.Overlay,
+		AnimationLimits: h.limits.Animation,
 	})
 
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		// error decode overlay dibungkus dari imageutil, jadi statusnya sama dengan saat download
+		return c.Status(imageErrorStatus(err)).JSON(fiber.Map{
 			"message": err.Error(),
 		})
 	}
@@ -61,6 +133,34 @@ func (h *Handler) GenerateMeme(c *fiber.Ctx) error {
 	return c.JSON(result)
 }
 
+// downloadOverlay mengunduh gambar overlay dan mengecek dimensinya sebelum didecode penuh
+func (h *Handler) downloadOverlay(url string) ([]byte, error) {
+	data, err := DownloadImageAsBytes(url)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, _, err := imageutil.CheckLimits(data, h.limits.Overlay); err != nil {
+		return nil, err
+	}
+	if err := imageutil.CheckAnimationLimits(data, h.limits.Animation); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// imageErrorStatus 413 untuk gambar atau animasi yang terlalu besar, 422 untuk gambar yang tidak bisa dibaca
+func imageErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, imageutil.ErrImageTooLarge), errors.Is(err, imageutil.ErrAnimationTooLong):
+		return fiber.StatusRequestEntityTooLarge
+	case errors.Is(err, imageutil.ErrInvalidImage):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return fiber.StatusBadRequest
+	}
+}
+
 func (h *Handler) GetAllPreset(c *fiber.Ctx) error {
 	presets := h.memeGenerator.GetAllPreset()
 	return c.JSON(presets)
@@ -94,9 +194,9 @@ func (h *Handler) UploadFile(c *fiber.Ctx) error {
 	}
 
 	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
-	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
+	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" && ext != ".gif" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "only JPG and PNG are allowed",
+			"message": "only JPG, PNG and GIF are allowed",
 		})
 	}
 
@@ -108,8 +208,29 @@ func (h *Handler) UploadFile(c *fiber.Ctx) error {
 	}
 	defer file.Close()
 
+	data, err := io.ReadAll(io.LimitReader(file, maxUploadSize))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to read file",
+		})
+	}
+
+	if err := imageutil.CheckAnimationLimits(data, h.limits.Animation); err != nil {
+		return c.Status(imageErrorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	// encode ulang supaya EXIF (termasuk lokasi GPS) user tidak ikut dipublikasikan
+	data, err = imageutil.StripMetadata(data, h.limits.Upload)
+	if err != nil {
+		return c.Status(imageErrorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
 	ctx := context.Background()
-	result, err := h.storageProvider.Upload(ctx, file)
+	result, err := h.storageProvider.UploadBytes(ctx, data)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"message": fmt.Sprintf("upload failed: %v", err),
@@ -119,9 +240,57 @@ func (h *Handler) UploadFile(c *fiber.Ctx) error {
 	return c.JSON(result)
 }
 
-func NewHandler(memeGenerator *meme.Generator, storageProvider port.StorageProvider) *Handler {
+const maxVerifySize = 10 * 1024 * 1024 // 10MB, GIF hasil generate bisa lebih besar dari batas upload
+
+// Verify memeriksa apakah gambar yang dikirim (form field "file") dibuat oleh server ini
+func (h *Handler) Verify(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "file not found",
+		})
+	}
+
+	if fileHeader.Size > maxVerifySize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "file too large (max 10MB)",
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to open file",
+		})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxVerifySize))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to read file",
+		})
+	}
+
+	result, err := h.memeGenerator.Verify(data, h.limits.Upload)
+	if errors.Is(err, meme.ErrProvenanceDisabled) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(imageErrorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.JSON(result)
+}
+
+func NewHandler(memeGenerator *meme.Generator, storageProvider port.StorageProvider, limits Limits) *Handler {
 	return &Handler{
 		memeGenerator:   memeGenerator,
 		storageProvider: storageProvider,
+		limits:          limits,
 	}
 }
@@ -15,6 +15,7 @@ var allowedTypes = map[string]bool{
 	"image/png":  true,
 	"image/jpeg": true,
 	"image/jpg":  true,
+	"image/gif":  true,
 }
 
 func DownloadImageAsBytes(url string) ([]byte, error) {
@@ -4,4 +4,5 @@ type Meme struct {
 	ImageUrl    string `json:"image_url"`
 	ContentType string `json:"content_type"`
 	Size        string `json:"size"`
+	RenderID    string `json:"render_id,omitempty"` // kosong bila provenance tidak aktif
 }
@@ -3,8 +3,13 @@ package preset
 import (
 	"MemeCraft/internal/service/imageutil"
 	"image"
+	"slices"
+	"time"
 )
 
+// DefaultOverlaySlot nama slot untuk preset lama yang hanya punya satu "overlay"
+const DefaultOverlaySlot = "default"
+
 type TextBox struct {
 	Name        string  `json:"name"`
 	X           float64 `json:"x"`
@@ -12,21 +17,143 @@ type TextBox struct {
 	Width       int     `json:"width"`
 	Height      int     `json:"height"`
 	Font        string  `json:"font,omitempty"`
+	BoldFont    string  `json:"bold_font,omitempty"` // dipakai untuk **tebal** di markup, kosong = faux bold
 	Size        float64 `json:"size,omitempty"`
 	Color       string  `json:"color,omitempty"`
-	Align       string  `json:"align,omitempty"` // "left", "center", "right"
+	Align       string  `json:"align,omitempty"` // "left", "center", "right", "justify", kosong = mengikuti arah teks
 	LineSpacing float64 `json:"line_spacing,omitempty"`
+	Wrap        string  `json:"wrap,omitempty"`    // "word" (default), "char", "none"
 	Padding     int     `json:"padding,omitempty"` // jarak dari tepi
 	MaxChars    int     `json:"max_chars,omitempty"`
 	Normalize   string  `json:"normalize,omitempty"` // "normal", "toupper", "tolower"
+	Direction   string  `json:"direction,omitempty"` // "auto" (default), "ltr", "rtl"
+
+	LetterSpacing float64  `json:"letter_spacing,omitempty"` // pixel tambahan antar huruf
+	FallbackFonts []string `json:"fallback_fonts,omitempty"` // dicoba berurutan bila huruf tidak ada di font
+
+	Rotate float64 `json:"rotate,omitempty"` // derajat terhadap titik tengah box
+	SkewX  float64 `json:"skew_x,omitempty"` // derajat, positif = bagian atas miring ke kanan
+
+	Overridable *StyleLimits `json:"overridable,omitempty"` // kosong = hanya warna yang boleh diganti request
+
+	Default         string    `json:"default,omitempty"` // dipakai bila request tidak mengirim teks, boleh berisi placeholder {{...}}
+	DefaultTemplate *Template `json:"-"`
+
+	BackgroundColor   string  `json:"background_color,omitempty"` // bar di belakang teks, kosong = tanpa background
+	BackgroundPadding float64 `json:"background_padding,omitempty"`
+	BackgroundRadius  float64 `json:"background_radius,omitempty"`
+	BackgroundMode    string  `json:"background_mode,omitempty"` // "line" (default) atau "block"
+}
+
+// StyleLimits properti text box yang boleh diganti per request beserta batasnya
+type StyleLimits struct {
+	Color     bool     `json:"color,omitempty"`
+	Palette   []string `json:"palette,omitempty"` // warna yang diizinkan (color tidak perlu diisi), kosong = semua warna solid
+	MinSize   float64  `json:"min_size,omitempty"`
+	MaxSize   float64  `json:"max_size,omitempty"` // 0 = size tidak boleh diganti
+	Align     []string `json:"align,omitempty"`
+	Normalize []string `json:"normalize,omitempty"`
 }
 
 type Overlay struct {
-	X      int     `json:"x"`
-	Y      int     `json:"y"`
-	Width  int     `json:"width"`
-	Height int     `json:"height"`
-	Rotate float64 `json:"rotate"`
+	Name       string                `json:"name"`
+	X          int                   `json:"x"`
+	Y          int                   `json:"y"`
+	Width      int                   `json:"width"`
+	Height     int                   `json:"height"`
+	Rotate     float64               `json:"rotate"`
+	ResizeMode imageutil.ResizeMode  `json:"resize_mode,omitempty"` // kosong = ikut resize_mode preset
+	Layer      string                `json:"layer,omitempty"`       // "back" (default) atau "front", diabaikan jika preset punya "layers"
+	Opacity    *float64              `json:"opacity,omitempty"`     // default untuk layer overlay slot ini
+	Blend      string                `json:"blend,omitempty"`       // default untuk layer overlay slot ini
+	Filters    []imageutil.Filter    `json:"filters,omitempty"`     // dijalankan setelah resize, bisa diganti per request
+	Focus      *imageutil.FocalPoint `json:"focus,omitempty"`       // titik fokus default untuk mode fill/smart
+	Background *imageutil.Background `json:"background,omitempty"`  // isi area kosong untuk mode contain
+	Mask       *imageutil.Mask       `json:"mask,omitempty"`        // bentuk slot, dipasang sebelum rotate
+	MaskAlpha  *image.Alpha          `json:"-"`                     // mask seukuran slot, dirender saat preset dimuat
+}
+
+type LayerType string
+
+const (
+	LayerBase    LayerType = "base"    // base image preset
+	LayerOverlay LayerType = "overlay" // slot overlay dari user, Ref = nama slot
+	LayerImage   LayerType = "image"   // gambar statis (sticker, logo)
+	LayerText    LayerType = "text"    // Ref = nama text box, kosong = semua text box
+	LayerShape   LayerType = "shape"
+	LayerCode    LayerType = "code" // QR code atau barcode, Ref = nama field di "codes" request
+)
+
+type Shape struct {
+	Kind        imageutil.ShapeKind `json:"kind"`            // "rect", "rounded_rect", "ellipse", "line", "gradient"
+	Color       string              `json:"color,omitempty"` // warna, linear-gradient(...) atau radial-gradient(...)
+	Radius      float64             `json:"radius,omitempty"`
+	From        string              `json:"from,omitempty"`
+	To          string              `json:"to,omitempty"`
+	Angle       float64             `json:"angle,omitempty"`
+	Stroke      string              `json:"stroke,omitempty"` // garis tepi, wajib untuk "line"
+	StrokeWidth float64             `json:"stroke_width,omitempty"`
+
+	// Overridable warna yang boleh diganti request lewat "shapes" dengan key nama layer.
+	// Hanya color dan palette yang berlaku, kosong = warna shape tetap.
+	Overridable *StyleLimits `json:"overridable,omitempty"`
+}
+
+// Code pengaturan layer "code", isinya dari request
+type Code struct {
+	Format          imageutil.CodeFormat `json:"format,omitempty"`           // "qr" (default), "code128", "ean"
+	Foreground      string               `json:"foreground,omitempty"`       // default hitam
+	Background      string               `json:"background,omitempty"`       // default putih
+	ErrorCorrection string               `json:"error_correction,omitempty"` // khusus QR: "L", "M" (default), "Q", "H"
+	Default         string               `json:"default,omitempty"`          // template seperti default text box, kosong = layer dilewati bila tidak dikirim
+	DefaultTemplate *Template            `json:"-"`
+}
+
+// Layer satu lapisan komposisi, digambar berurutan dari bawah ke atas
+type Layer struct {
+	Name         string      `json:"name,omitempty"`
+	Type         LayerType   `json:"type"`
+	Ref          string      `json:"ref,omitempty"`
+	Image        string      `json:"image,omitempty"` // path gambar untuk layer "image", dengan variants = gambar bila request tidak memilih
+	X            int         `json:"x,omitempty"`
+	Y            int         `json:"y,omitempty"`
+	Width        int         `json:"width,omitempty"`
+	Height       int         `json:"height,omitempty"`
+	Shape        *Shape      `json:"shape,omitempty"`
+	Code         *Code       `json:"code,omitempty"`
+	Opacity      *float64    `json:"opacity,omitempty"` // 0..1, default 1
+	Blend        string      `json:"blend,omitempty"`   // "normal" (default), "multiply", "screen", "overlay", "darken", "lighten", "soft-light", "difference"
+	ImageDecoded image.Image `json:"-"`
+
+	// Variant nama pilihan di request (mis. "brand"), Variants nilai pilihan => path gambar.
+	// Tanpa image, layer dilewati bila request tidak memilih.
+	Variant         string                 `json:"variant,omitempty"`
+	Variants        map[string]string      `json:"variants,omitempty"`
+	VariantsDecoded map[string]image.Image `json:"-"`
+}
+
+// VariantOptions nilai yang bisa dipilih request untuk setiap variant, terurut
+func (p *Preset) VariantOptions() map[string][]string {
+	options := make(map[string][]string)
+	for _, l := range p.Layers {
+		for v := range l.Variants {
+			if !slices.Contains(options[l.Variant], v) {
+				options[l.Variant] = append(options[l.Variant], v)
+			}
+		}
+	}
+	for _, values := range options {
+		slices.Sort(values)
+	}
+	return options
+}
+
+// Alpha mengembalikan opacity layer, default 1
+func (l *Layer) Alpha() float64 {
+	if l.Opacity == nil {
+		return 1
+	}
+	return *l.Opacity
 }
 
 type Preset struct {
@@ -36,6 +163,42 @@ type Preset struct {
 	ExampleImage     string               `json:"example_image"`
 	ResizeMode       imageutil.ResizeMode `json:"resize_mode"`
 	BaseImageDecoded image.Image          `json:"-"`
-	Overlay          Overlay              `json:"overlay"`
+	Overlay          *Overlay             `json:"overlay,omitempty"` // deprecated, gunakan Overlays
+	Overlays         []Overlay            `json:"overlays"`
 	TextBoxes        []TextBox            `json:"text_boxes"`
+	Layers           []Layer              `json:"layers,omitempty"`   // kosong = susunan default dari overlays
+	Locale           string               `json:"locale,omitempty"`   // nama bulan/hari untuk {{date:...}}, "en" (default) atau "id"
+	Timezone         string               `json:"timezone,omitempty"` // nama IANA untuk {{date:...}}, kosong = UTC
+	Location         *time.Location       `json:"-"`
+	NoWatermark      bool                 `json:"no_watermark,omitempty"` // preset tidak diberi watermark server
+}
+
+// TextBox mencari text box berdasarkan nama
+func (p *Preset) TextBox(name string) (*TextBox, bool) {
+	for i := range p.TextBoxes {
+		if p.TextBoxes[i].Name == name {
+			return &p.TextBoxes[i], true
+		}
+	}
+	return nil, false
+}
+
+// CodeLayer mencari layer code berdasarkan nama field request
+func (p *Preset) CodeLayer(ref string) (*Layer, bool) {
+	for i := range p.Layers {
+		if p.Layers[i].Type == LayerCode && p.Layers[i].Ref == ref {
+			return &p.Layers[i], true
+		}
+	}
+	return nil, false
+}
+
+// OverlaySlot mencari slot overlay berdasarkan nama
+func (p *Preset) OverlaySlot(name string) (*Overlay, bool) {
+	for i := range p.Overlays {
+		if p.Overlays[i].Name == name {
+			return &p.Overlays[i], true
+		}
+	}
+	return nil, false
 }
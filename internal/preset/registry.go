@@ -1,11 +1,16 @@
 package preset
 
 import (
+	"MemeCraft/internal/service/imageutil"
 	"encoding/json"
+	"fmt"
 	"image"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 )
 
 type Registry struct {
@@ -14,21 +19,39 @@ type Registry struct {
 }
 
 type TextBoxSummary struct {
-	Name     string `json:"name"`
-	MaxChars int    `json:"max_chars"`
+	Name        string       `json:"name"`
+	MaxChars    int          `json:"max_chars"`
+	Overridable *StyleLimits `json:"overridable"`
+	Default     string       `json:"default,omitempty"` // template, diisi server bila teks tidak dikirim
 }
 
 type OverlaySummary struct {
-	Width  int `json:"width"`
-	Height int `json:"height"`
+	Name   string `json:"name"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
 }
 
 type PresetSummary struct {
-	Name         string           `json:"name"`
-	ID           string           `json:"id"`
-	ExampleImage string           `json:"example_image"`
-	Overlay      OverlaySummary   `json:"overlay"`
-	TextBoxes    []TextBoxSummary `json:"text_boxes"`
+	Name         string              `json:"name"`
+	ID           string              `json:"id"`
+	ExampleImage string              `json:"example_image"`
+	Overlay      OverlaySummary      `json:"overlay"` // slot pertama, untuk client lama
+	Overlays     []OverlaySummary    `json:"overlays"`
+	TextBoxes    []TextBoxSummary    `json:"text_boxes"`
+	Variants     map[string][]string `json:"variants"` // key = nama variant, value = pilihan untuk request
+	Codes        []CodeSummary       `json:"codes"`
+	Shapes       []ShapeSummary      `json:"shapes"` // shape yang warnanya bisa diganti request
+}
+
+type ShapeSummary struct {
+	Name        string       `json:"name"` // key di "shapes" request
+	Overridable *StyleLimits `json:"overridable"`
+}
+
+type CodeSummary struct {
+	Name    string               `json:"name"` // key di "codes" request
+	Format  imageutil.CodeFormat `json:"format"`
+	Default string               `json:"default,omitempty"`
 }
 
 func NewRegistry() *Registry {
@@ -59,27 +82,77 @@ func (r *Registry) LoadFromDir(dir string) error {
 			return err
 		}
 
-		// BaseImage dianggap relatif terhadap workdir
-		imgPath := filepath.Clean(p.BaseImage)
-		log.Println("loading base image =>", imgPath)
-
-		imgFile, err := os.Open(imgPath)
-		if err != nil {
+		if err := normalizeOverlays(&p); err != nil {
 			return err
 		}
-		defer imgFile.Close()
 
-		img, _, err := image.Decode(imgFile)
+		// BaseImage dianggap relatif terhadap workdir
+		log.Println("loading base image =>", filepath.Clean(p.BaseImage))
+		img, err := loadImage(p.BaseImage)
 		if err != nil {
 			return err
 		}
 		p.BaseImageDecoded = img
 
+		if err := normalizeLayers(&p); err != nil {
+			return err
+		}
+
+		if err := normalizeTemplates(&p); err != nil {
+			return err
+		}
+
 		for i := range p.TextBoxes {
 			if p.TextBoxes[i].Font != "" {
 				p.TextBoxes[i].Font = filepath.Clean(p.TextBoxes[i].Font)
 				log.Println("registered font for box", p.TextBoxes[i].Name, "=>", p.TextBoxes[i].Font)
 			}
+			if p.TextBoxes[i].BoldFont != "" {
+				p.TextBoxes[i].BoldFont = filepath.Clean(p.TextBoxes[i].BoldFont)
+				log.Println("registered bold font for box", p.TextBoxes[i].Name, "=>", p.TextBoxes[i].BoldFont)
+			}
+			for j, path := range p.TextBoxes[i].FallbackFonts {
+				p.TextBoxes[i].FallbackFonts[j] = filepath.Clean(path)
+				log.Println("registered fallback font for box", p.TextBoxes[i].Name, "=>", p.TextBoxes[i].FallbackFonts[j])
+			}
+			// pattern di color dibaca di sini sehingga path yang salah ketahuan saat load
+			if p.TextBoxes[i].Color != "" {
+				if _, err := imageutil.ParsePaint(p.TextBoxes[i].Color); err != nil {
+					return fmt.Errorf("preset %s: text box %q: %w", p.ID, p.TextBoxes[i].Name, err)
+				}
+			}
+			switch p.TextBoxes[i].Align {
+			case "", "left", "center", "right", "justify":
+			default:
+				return fmt.Errorf("preset %s: text box %q: invalid align %q", p.ID, p.TextBoxes[i].Name, p.TextBoxes[i].Align)
+			}
+			switch p.TextBoxes[i].Wrap {
+			case "", imageutil.WrapWord, imageutil.WrapChar, imageutil.WrapNone:
+			default:
+				return fmt.Errorf("preset %s: text box %q: invalid wrap mode %q", p.ID, p.TextBoxes[i].Name, p.TextBoxes[i].Wrap)
+			}
+			switch p.TextBoxes[i].Direction {
+			case "", imageutil.DirectionAuto, imageutil.DirectionLTR, imageutil.DirectionRTL:
+			default:
+				return fmt.Errorf("preset %s: text box %q: invalid direction %q", p.ID, p.TextBoxes[i].Name, p.TextBoxes[i].Direction)
+			}
+			if p.TextBoxes[i].BackgroundColor != "" {
+				if _, err := imageutil.ParseColor(p.TextBoxes[i].BackgroundColor); err != nil {
+					return fmt.Errorf("preset %s: text box %q: background: %w", p.ID, p.TextBoxes[i].Name, err)
+				}
+			}
+			switch p.TextBoxes[i].BackgroundMode {
+			case "", imageutil.TextBackgroundLine, imageutil.TextBackgroundBlock:
+			default:
+				return fmt.Errorf("preset %s: text box %q: invalid background mode %q", p.ID, p.TextBoxes[i].Name, p.TextBoxes[i].BackgroundMode)
+			}
+			if err := normalizeStyleLimits(&p.TextBoxes[i]); err != nil {
+				return fmt.Errorf("preset %s: text box %q: %w", p.ID, p.TextBoxes[i].Name, err)
+			}
+			// skew mendekati 90 derajat membuat teks tidak terbaca dan gambarnya sangat lebar
+			if math.Abs(p.TextBoxes[i].SkewX) >= 80 {
+				return fmt.Errorf("preset %s: text box %q: invalid skew_x %v", p.ID, p.TextBoxes[i].Name, p.TextBoxes[i].SkewX)
+			}
 		}
 
 		r.presets[p.ID] = &p
@@ -96,64 +169,462 @@ func (r *Registry) Get(presetId string) (*Preset, bool) {
 	return p, ok
 }
 
+// IDs semua preset yang dimuat
+func (r *Registry) IDs() []string {
+	ids := make([]string, 0, len(r.presets))
+	for id := range r.presets {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
 func (r *Registry) GetSummaryById(presetId string) (*PresetSummary, bool) {
 	p, ok := r.presets[presetId]
 	if !ok {
 		return nil, false
 	}
 
-	textbox := make([]TextBoxSummary, len(p.TextBoxes))
-	for i, tb := range p.TextBoxes {
-		textbox[i] = TextBoxSummary{
-			Name:     tb.Name,
-			MaxChars: tb.MaxChars,
-		}
+	return newPresetSummary(p), true
+}
+
+func (r *Registry) GetAll() []*PresetSummary {
+	if r.cache != nil {
+		return r.cache
+	}
+
+	summaries := make([]*PresetSummary, 0, len(r.presets))
+	for _, p := range r.presets {
+		summaries = append(summaries, newPresetSummary(p))
+	}
+
+	r.cache = summaries
+
+	return summaries
+}
+
+func newPresetSummary(p *Preset) *PresetSummary {
+	overlays := make([]OverlaySummary, 0, len(p.Overlays))
+	for _, o := range p.Overlays {
+		overlays = append(overlays, OverlaySummary{
+			Name:   o.Name,
+			Width:  o.Width,
+			Height: o.Height,
+		})
+	}
+
+	var overlay OverlaySummary
+	if len(overlays) > 0 {
+		overlay = overlays[0]
+	}
+
+	tbSummaries := make([]TextBoxSummary, 0, len(p.TextBoxes))
+	for _, tb := range p.TextBoxes {
+		tbSummaries = append(tbSummaries, TextBoxSummary{
+			Name:        tb.Name,
+			MaxChars:    tb.MaxChars,
+			Overridable: tb.Overridable,
+			Default:     tb.Default,
+		})
 	}
 
 	return &PresetSummary{
 		Name:         p.Name,
 		ID:           p.ID,
 		ExampleImage: p.ExampleImage,
-		Overlay: OverlaySummary{
-			Width:  p.Overlay.Width,
-			Height: p.Overlay.Height,
-		},
-		TextBoxes: textbox,
-	}, true
+		Overlay:      overlay,
+		Overlays:     overlays,
+		TextBoxes:    tbSummaries,
+		Variants:     p.VariantOptions(),
+		Codes:        codeSummaries(p),
+		Shapes:       shapeSummaries(p),
+	}
 }
 
-func (r *Registry) GetAll() []*PresetSummary {
-	if r.cache != nil {
-		return r.cache
+func codeSummaries(p *Preset) []CodeSummary {
+	codes := make([]CodeSummary, 0)
+	for _, l := range p.Layers {
+		if l.Type != LayerCode || slices.ContainsFunc(codes, func(c CodeSummary) bool { return c.Name == l.Ref }) {
+			continue
+		}
+		format := l.Code.Format
+		if format == "" {
+			format = imageutil.CodeQR
+		}
+		codes = append(codes, CodeSummary{Name: l.Ref, Format: format, Default: l.Code.Default})
 	}
+	return codes
+}
 
-	summaries := make([]*PresetSummary, 0, len(r.presets))
-	for _, p := range r.presets {
-		overlay := OverlaySummary{
-			Width:  p.Overlay.Width,
-			Height: p.Overlay.Height,
+func shapeSummaries(p *Preset) []ShapeSummary {
+	shapes := make([]ShapeSummary, 0)
+	for _, l := range p.Layers {
+		if l.Type != LayerShape || l.Shape.Overridable == nil || slices.ContainsFunc(shapes, func(s ShapeSummary) bool { return s.Name == l.Name }) {
+			continue
 		}
+		shapes = append(shapes, ShapeSummary{Name: l.Name, Overridable: l.Shape.Overridable})
+	}
+	return shapes
+}
 
-		tbSummaries := make([]TextBoxSummary, 0, len(p.TextBoxes))
-		for _, tb := range p.TextBoxes {
-			tbSummaries = append(tbSummaries, TextBoxSummary{
-				Name:     tb.Name,
-				MaxChars: tb.MaxChars,
-			})
+// normalizeOverlays mengubah field "overlay" lama menjadi slot "default"
+// dan memvalidasi nama serta layer tiap slot
+func normalizeOverlays(p *Preset) error {
+	if len(p.Overlays) == 0 && p.Overlay != nil {
+		p.Overlays = []Overlay{*p.Overlay}
+	}
+	p.Overlay = nil
+
+	if len(p.Overlays) == 0 {
+		return fmt.Errorf("preset %s: no overlay slot defined", p.ID)
+	}
+
+	seen := make(map[string]bool, len(p.Overlays))
+	for i := range p.Overlays {
+		o := &p.Overlays[i]
+		if o.Name == "" {
+			if len(p.Overlays) > 1 {
+				return fmt.Errorf("preset %s: overlay slot #%d has no name", p.ID, i)
+			}
+			o.Name = DefaultOverlaySlot
+		}
+		if seen[o.Name] {
+			return fmt.Errorf("preset %s: duplicate overlay slot %q", p.ID, o.Name)
+		}
+		seen[o.Name] = true
+
+		// ukuran slot dipakai sebagai pembagi saat resize, slot tanpa ukuran ditolak di sini
+		if o.Width <= 0 || o.Height <= 0 {
+			return fmt.Errorf("preset %s: overlay slot %q needs positive width and height", p.ID, o.Name)
+		}
+
+		if o.Focus != nil {
+			if err := o.Focus.Validate(); err != nil {
+				return fmt.Errorf("preset %s: overlay slot %q: %w", p.ID, o.Name, err)
+			}
 		}
 
-		summary := &PresetSummary{
-			Name:         p.Name,
-			ID:           p.ID,
-			ExampleImage: p.ExampleImage,
-			Overlay:      overlay,
-			TextBoxes:    tbSummaries,
+		if o.Background != nil {
+			if err := o.Background.Validate(); err != nil {
+				return fmt.Errorf("preset %s: overlay slot %q: %w", p.ID, o.Name, err)
+			}
 		}
 
-		summaries = append(summaries, summary)
+		for _, f := range o.Filters {
+			if err := f.Validate(); err != nil {
+				return fmt.Errorf("preset %s: overlay slot %q: %w", p.ID, o.Name, err)
+			}
+		}
+
+		if o.Mask != nil {
+			if err := loadMask(o); err != nil {
+				return fmt.Errorf("preset %s: overlay slot %q: %w", p.ID, o.Name, err)
+			}
+		}
+
+		switch o.Layer {
+		case "":
+			o.Layer = "back"
+		case "back", "front":
+		default:
+			return fmt.Errorf("preset %s: invalid layer %q for overlay slot %q", p.ID, o.Layer, o.Name)
+		}
 	}
 
-	r.cache = summaries
+	return nil
+}
 
-	return summaries
+// loadMask merender mask slot sekali, ukuran slot tidak berubah antar request
+func loadMask(o *Overlay) error {
+	if err := o.Mask.Validate(); err != nil {
+		return err
+	}
+	var src image.Image
+	if o.Mask.Kind == imageutil.MaskImage {
+		log.Println("loading mask for slot", o.Name, "=>", filepath.Clean(o.Mask.Image))
+		img, err := loadImage(o.Mask.Image)
+		if err != nil {
+			return fmt.Errorf("mask: %w", err)
+		}
+		src = img
+	}
+
+	alpha, err := o.Mask.Render(o.Width, o.Height, src)
+	if err != nil {
+		return err
+	}
+	o.MaskAlpha = alpha
+	return nil
+}
+
+// normalizeLayers mengisi susunan layer default (overlay "back", base, overlay "front", text)
+// bila preset tidak mendefinisikan "layers", lalu memvalidasi dan memuat gambar statis
+func normalizeLayers(p *Preset) error {
+	if len(p.Layers) == 0 {
+		for _, o := range p.Overlays {
+			if o.Layer == "back" {
+				p.Layers = append(p.Layers, Layer{Type: LayerOverlay, Ref: o.Name})
+			}
+		}
+		p.Layers = append(p.Layers, Layer{Type: LayerBase})
+		for _, o := range p.Overlays {
+			if o.Layer == "front" {
+				p.Layers = append(p.Layers, Layer{Type: LayerOverlay, Ref: o.Name})
+			}
+		}
+		p.Layers = append(p.Layers, Layer{Type: LayerText})
+	}
+
+	bases := 0
+	usedSlots := make(map[string]bool, len(p.Overlays))
+	for i := range p.Layers {
+		l := &p.Layers[i]
+
+		switch l.Type {
+		case LayerBase:
+			bases++
+		case LayerOverlay:
+			slot, ok := p.OverlaySlot(l.Ref)
+			if !ok {
+				return fmt.Errorf("preset %s: layer #%d references unknown overlay slot %q", p.ID, i, l.Ref)
+			}
+			usedSlots[l.Ref] = true
+
+			// layer overlay mewarisi opacity dan blend dari slot bila tidak diisi
+			if l.Opacity == nil {
+				l.Opacity = slot.Opacity
+			}
+			if l.Blend == "" {
+				l.Blend = slot.Blend
+			}
+		case LayerText:
+			if _, ok := p.TextBox(l.Ref); l.Ref != "" && !ok {
+				return fmt.Errorf("preset %s: layer #%d references unknown text box %q", p.ID, i, l.Ref)
+			}
+		case LayerImage:
+			if err := loadImageLayer(p.ID, i, l); err != nil {
+				return err
+			}
+		case LayerCode:
+			if l.Code == nil || l.Ref == "" || l.Width <= 0 || l.Height <= 0 {
+				return fmt.Errorf("preset %s: layer #%d needs code, ref, width and height", p.ID, i)
+			}
+			code := imageutil.Code{Format: l.Code.Format, Foreground: l.Code.Foreground, Background: l.Code.Background, ErrorCorrection: l.Code.ErrorCorrection}
+			if err := code.Validate(); err != nil {
+				return fmt.Errorf("preset %s: layer #%d: %w", p.ID, i, err)
+			}
+		case LayerShape:
+			if err := validateShapeLayer(p.ID, i, l); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("preset %s: layer #%d has invalid type %q", p.ID, i, l.Type)
+		}
+
+		if o := l.Alpha(); o < 0 || o > 1 {
+			return fmt.Errorf("preset %s: layer #%d opacity must be between 0 and 1", p.ID, i)
+		}
+		mode, err := imageutil.ParseBlendMode(l.Blend)
+		if err != nil {
+			return fmt.Errorf("preset %s: layer #%d: %w", p.ID, i, err)
+		}
+		l.Blend = string(mode)
+	}
+
+	if bases != 1 {
+		return fmt.Errorf("preset %s: layers must contain exactly one base layer", p.ID)
+	}
+	for _, o := range p.Overlays {
+		if !usedSlots[o.Name] {
+			return fmt.Errorf("preset %s: overlay slot %q is not used by any layer", p.ID, o.Name)
+		}
+	}
+
+	return nil
+}
+
+// validateShapeLayer memeriksa layer shape. Garis boleh lebar atau tinggi 0 (bahkan negatif
+// untuk garis miring ke atas), shape lain butuh area.
+func validateShapeLayer(presetID string, i int, l *Layer) error {
+	if l.Shape == nil {
+		return fmt.Errorf("preset %s: layer #%d needs shape, width and height", presetID, i)
+	}
+	if l.Shape.Kind == imageutil.ShapeLine {
+		if l.Width == 0 && l.Height == 0 {
+			return fmt.Errorf("preset %s: layer #%d line needs width or height", presetID, i)
+		}
+	} else if l.Width <= 0 || l.Height <= 0 {
+		return fmt.Errorf("preset %s: layer #%d needs shape, width and height", presetID, i)
+	}
+
+	shape := imageutil.Shape{
+		Kind:        l.Shape.Kind,
+		Color:       l.Shape.Color,
+		From:        l.Shape.From,
+		To:          l.Shape.To,
+		Stroke:      l.Shape.Stroke,
+		StrokeWidth: l.Shape.StrokeWidth,
+	}
+	if err := shape.Validate(); err != nil {
+		return fmt.Errorf("preset %s: layer #%d: %w", presetID, i, err)
+	}
+
+	if o := l.Shape.Overridable; o != nil {
+		switch {
+		case l.Name == "":
+			return fmt.Errorf("preset %s: layer #%d overridable shape needs a name", presetID, i)
+		case l.Shape.Kind == imageutil.ShapeGradient:
+			return fmt.Errorf("preset %s: layer #%d gradient shape can't be overridable, use color with linear-gradient", presetID, i)
+		case o.MaxSize != 0 || o.MinSize != 0 || len(o.Align) > 0 || len(o.Normalize) > 0:
+			return fmt.Errorf("preset %s: layer #%d shape only allows color and palette overrides", presetID, i)
+		case !o.Color && len(o.Palette) == 0:
+			return fmt.Errorf("preset %s: layer #%d overridable shape needs color or palette", presetID, i)
+		}
+		for _, c := range o.Palette {
+			if _, err := imageutil.ParseColor(c); err != nil {
+				return fmt.Errorf("preset %s: layer #%d overridable palette: %w", presetID, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeTemplates memvalidasi locale dan timezone preset lalu mem-parse default text box
+// dan layer code
+func normalizeTemplates(p *Preset) error {
+	if p.Locale == "" {
+		p.Locale = "en"
+	}
+	if _, ok := locales[p.Locale]; !ok {
+		return fmt.Errorf("preset %s: unsupported locale %q", p.ID, p.Locale)
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return fmt.Errorf("preset %s: invalid timezone %q: %w", p.ID, p.Timezone, err)
+	}
+	p.Location = loc
+
+	for i := range p.TextBoxes {
+		tb := &p.TextBoxes[i]
+		if tb.Default == "" {
+			continue
+		}
+		tmpl, err := ParseTemplate(tb.Default)
+		if err != nil {
+			return fmt.Errorf("preset %s: text box %q: default: %w", p.ID, tb.Name, err)
+		}
+		for _, field := range tmpl.Fields() {
+			// default hanya dipakai bila teks box ini kosong, jadi referensi ke diri sendiri selalu kosong
+			if _, ok := p.TextBox(field); !ok || field == tb.Name {
+				return fmt.Errorf("preset %s: text box %q: default references invalid text box %q", p.ID, tb.Name, field)
+			}
+		}
+		tb.DefaultTemplate = tmpl
+	}
+
+	for i := range p.Layers {
+		l := &p.Layers[i]
+		if l.Type != LayerCode || l.Code.Default == "" {
+			continue
+		}
+		tmpl, err := ParseTemplate(l.Code.Default)
+		if err != nil {
+			return fmt.Errorf("preset %s: layer #%d: default: %w", p.ID, i, err)
+		}
+		for _, field := range tmpl.Fields() {
+			if _, ok := p.TextBox(field); !ok {
+				return fmt.Errorf("preset %s: layer #%d: default references invalid text box %q", p.ID, i, field)
+			}
+		}
+		l.Code.DefaultTemplate = tmpl
+	}
+	return nil
+}
+
+// loadImageLayer memuat gambar layer "image" beserta semua variantnya sekali saat load
+func loadImageLayer(presetID string, i int, l *Layer) error {
+	if (l.Variant == "") != (len(l.Variants) == 0) {
+		return fmt.Errorf("preset %s: layer #%d needs both variant and variants", presetID, i)
+	}
+	if l.Image == "" && len(l.Variants) == 0 {
+		return fmt.Errorf("preset %s: layer #%d has no image", presetID, i)
+	}
+
+	if l.Image != "" {
+		img, err := loadImage(l.Image)
+		if err != nil {
+			return fmt.Errorf("preset %s: layer #%d: %w", presetID, i, err)
+		}
+		l.ImageDecoded = resizeStatic(img, l.Width, l.Height)
+	}
+
+	l.VariantsDecoded = make(map[string]image.Image, len(l.Variants))
+	for name, path := range l.Variants {
+		log.Println("loading variant", l.Variant+"="+name, "=>", filepath.Clean(path))
+		img, err := loadImage(path)
+		if err != nil {
+			return fmt.Errorf("preset %s: layer #%d: variant %q: %w", presetID, i, name, err)
+		}
+		l.VariantsDecoded[name] = resizeStatic(img, l.Width, l.Height)
+	}
+	return nil
+}
+
+// resizeStatic menyesuaikan ukuran gambar statis: lebar+tinggi = stretch, hanya lebar = lock ratio
+func resizeStatic(img image.Image, width, height int) image.Image {
+	switch {
+	case width > 0 && height > 0:
+		return imageutil.ResizeWithoutLockRatio(img, width, height)
+	case width > 0:
+		return imageutil.ResizeWithLockRatio(img, width)
+	default:
+		return img
+	}
+}
+
+// normalizeStyleLimits memvalidasi batas override text box. Tanpa overridable request hanya
+// boleh mengganti warna, sama seperti sebelum batas bisa diatur.
+func normalizeStyleLimits(tb *TextBox) error {
+	if tb.Overridable == nil {
+		tb.Overridable = &StyleLimits{Color: true}
+		return nil
+	}
+	l := tb.Overridable
+
+	for _, c := range l.Palette {
+		if _, err := imageutil.ParseColor(c); err != nil {
+			return fmt.Errorf("overridable palette: %w", err)
+		}
+	}
+	if l.MaxSize > 0 && (l.MinSize <= 0 || l.MinSize > l.MaxSize) {
+		return fmt.Errorf("overridable size: min_size must be between 0 and max_size")
+	}
+	for _, a := range l.Align {
+		switch a {
+		case "left", "center", "right", "justify":
+		default:
+			return fmt.Errorf("overridable: invalid align %q", a)
+		}
+	}
+	for _, n := range l.Normalize {
+		switch n {
+		case "normal", "toupper", "tolower":
+		default:
+			return fmt.Errorf("overridable: invalid normalize %q", n)
+		}
+	}
+	return nil
+}
+
+func loadImage(path string) (image.Image, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
 }
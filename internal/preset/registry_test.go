@@ -0,0 +1,75 @@
+package preset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeOverlays_SlotSize(t *testing.T) {
+	for _, o := range []Overlay{
+		{Name: "photo", Height: 400},
+		{Name: "photo", Width: 400},
+		{Name: "photo", Width: -1, Height: 400},
+	} {
+		p := &Preset{ID: "test", Overlays: []Overlay{o}}
+		assert.ErrorContains(t, normalizeOverlays(p), "needs positive width and height", "%+v", o)
+	}
+
+	p := &Preset{ID: "test", Overlays: []Overlay{{Name: "photo", Width: 400, Height: 300}}}
+	assert.NoError(t, normalizeOverlays(p))
+}
+
+func TestNormalizeOverlays_LegacyOverlay(t *testing.T) {
+	p := &Preset{ID: "test", Overlay: &Overlay{X: 10, Y: 20, Width: 400, Height: 300}}
+	require.NoError(t, normalizeOverlays(p))
+
+	assert.Nil(t, p.Overlay)
+	require.Len(t, p.Overlays, 1)
+	assert.Equal(t, DefaultOverlaySlot, p.Overlays[0].Name)
+	assert.Equal(t, "back", p.Overlays[0].Layer)
+	assert.Equal(t, 10, p.Overlays[0].X)
+}
+
+func TestNormalizeLayers_Default(t *testing.T) {
+	p := &Preset{ID: "test", Overlays: []Overlay{
+		{Name: "a", Width: 10, Height: 10},
+		{Name: "b", Width: 10, Height: 10, Layer: "front"},
+		{Name: "c", Width: 10, Height: 10, Layer: "back"},
+	}}
+	require.NoError(t, normalizeOverlays(p))
+	require.NoError(t, normalizeLayers(p))
+
+	assert.Equal(t, []Layer{
+		{Type: LayerOverlay, Ref: "a", Blend: "normal"},
+		{Type: LayerOverlay, Ref: "c", Blend: "normal"},
+		{Type: LayerBase, Blend: "normal"},
+		{Type: LayerOverlay, Ref: "b", Blend: "normal"},
+		{Type: LayerText, Blend: "normal"},
+	}, p.Layers)
+}
+
+func TestNormalizeLayers_Errors(t *testing.T) {
+	half, over := 0.5, 1.5
+	cases := []struct {
+		layers []Layer
+		want   string
+	}{
+		{[]Layer{{Type: LayerOverlay, Ref: "photo"}}, "exactly one base layer"},
+		{[]Layer{{Type: LayerBase}, {Type: LayerBase}, {Type: LayerOverlay, Ref: "photo"}}, "exactly one base layer"},
+		{[]Layer{{Type: LayerBase}}, `overlay slot "photo" is not used`},
+		{[]Layer{{Type: LayerBase}, {Type: LayerOverlay, Ref: "missing"}}, `unknown overlay slot "missing"`},
+		{[]Layer{{Type: LayerBase}, {Type: LayerOverlay, Ref: "photo"}, {Type: LayerText, Ref: "missing"}}, `unknown text box "missing"`},
+		{[]Layer{{Type: LayerBase}, {Type: LayerOverlay, Ref: "photo"}, {Type: "sticker"}}, `invalid type "sticker"`},
+		{[]Layer{{Type: LayerBase}, {Type: LayerOverlay, Ref: "photo"}, {Type: LayerImage}}, "has no image"},
+		{[]Layer{{Type: LayerBase}, {Type: LayerOverlay, Ref: "photo", Opacity: &over}}, "opacity must be between 0 and 1"},
+		{[]Layer{{Type: LayerBase}, {Type: LayerOverlay, Ref: "photo", Opacity: &half, Blend: "burn"}}, "burn"},
+		{[]Layer{{Type: LayerBase}, {Type: LayerOverlay, Ref: "photo"}, {Type: LayerShape, Width: 10}}, "needs shape, width and height"},
+	}
+	for _, c := range cases {
+		p := &Preset{ID: "test", Overlays: []Overlay{{Name: "photo", Width: 10, Height: 10}}, Layers: c.layers}
+		require.NoError(t, normalizeOverlays(p))
+		assert.ErrorContains(t, normalizeLayers(p), c.want, "%+v", c.layers)
+	}
+}
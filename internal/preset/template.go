@@ -0,0 +1,211 @@
+package preset
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Template teks default text box dengan placeholder:
+//
+//	{{date:02 Jan 2006}}  tanggal request dengan layout Go, nama bulan dan hari mengikuti locale preset
+//	{{.headline}}         teks request untuk text box headline
+//	{{upper .headline}}   upper atau lower untuk placeholder lain
+//	{{env.BRAND}}         environment variable server MEMECRAFT_TPL_BRAND
+//
+// Hanya default dari preset yang dievaluasi, teks dari request tidak pernah diproses sebagai template.
+// env hanya membaca variable berprefix templateEnvPrefix supaya secret server (key provenance,
+// kredensial storage) tidak bisa ditulis ke gambar publik lewat preset.
+type Template struct {
+	parts []templatePart
+}
+
+// templateEnvPrefix prefix environment variable yang bisa dibaca {{env.NAME}}
+const templateEnvPrefix = "MEMECRAFT_TPL_"
+
+type templatePart struct {
+	literal string
+	expr    *templateExpr // nil = literal
+}
+
+type templateExpr struct {
+	fn   string // "", "upper" atau "lower"
+	kind string // "date", "env" atau "field"
+	arg  string
+}
+
+func ParseTemplate(s string) (*Template, error) {
+	t := &Template{}
+	for {
+		start := strings.Index(s, "{{")
+		if start < 0 {
+			t.parts = append(t.parts, templatePart{literal: s})
+			return t, nil
+		}
+		end := strings.Index(s[start:], "}}")
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed placeholder %q", s[start:])
+		}
+		expr, err := parseTemplateExpr(strings.TrimSpace(s[start+2 : start+end]))
+		if err != nil {
+			return nil, err
+		}
+		t.parts = append(t.parts, templatePart{literal: s[:start]}, templatePart{expr: expr})
+		s = s[start+end+2:]
+	}
+}
+
+func parseTemplateExpr(s string) (*templateExpr, error) {
+	e := &templateExpr{}
+	if fn, rest, ok := strings.Cut(s, " "); ok && (fn == "upper" || fn == "lower") {
+		e.fn, s = fn, strings.TrimSpace(rest)
+	}
+
+	switch {
+	case strings.HasPrefix(s, "date:"):
+		e.kind, e.arg = "date", strings.TrimPrefix(s, "date:")
+		if strings.TrimSpace(e.arg) == "" {
+			return nil, fmt.Errorf("placeholder {{%s}}: missing date layout", s)
+		}
+	case strings.HasPrefix(s, "env."):
+		e.kind, e.arg = "env", strings.TrimPrefix(s, "env.")
+		if !isIdentifier(e.arg) {
+			return nil, fmt.Errorf("placeholder {{%s}}: invalid environment variable name", s)
+		}
+	case strings.HasPrefix(s, "."):
+		e.kind, e.arg = "field", strings.TrimPrefix(s, ".")
+		if e.arg == "" || strings.ContainsAny(e.arg, " \t") {
+			return nil, fmt.Errorf("placeholder {{%s}}: invalid text box name", s)
+		}
+	default:
+		return nil, fmt.Errorf("invalid placeholder {{%s}}", s)
+	}
+	return e, nil
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Fields nama text box yang dipakai template
+func (t *Template) Fields() []string {
+	var fields []string
+	for _, p := range t.parts {
+		if p.expr != nil && p.expr.kind == "field" {
+			fields = append(fields, p.expr.arg)
+		}
+	}
+	return fields
+}
+
+// Execute mengisi placeholder. values teks dari request, now waktu request di timezone preset.
+// Nilai yang dimasukkan tidak dievaluasi ulang, jadi "{{...}}" dari user tetap teks biasa.
+func (t *Template) Execute(values map[string]string, now time.Time, locale string) string {
+	var sb strings.Builder
+	for _, p := range t.parts {
+		if p.expr == nil {
+			sb.WriteString(p.literal)
+			continue
+		}
+
+		var v string
+		switch p.expr.kind {
+		case "date":
+			v = formatDate(now, p.expr.arg, locale)
+		case "env":
+			v = os.Getenv(templateEnvPrefix + p.expr.arg)
+		case "field":
+			v = values[p.expr.arg]
+		}
+		switch p.expr.fn {
+		case "upper":
+			v = strings.ToUpper(v)
+		case "lower":
+			v = strings.ToLower(v)
+		}
+		sb.WriteString(v)
+	}
+	return sb.String()
+}
+
+// dateNames nama bulan dan hari untuk satu locale
+type dateNames struct {
+	months, shortMonths [12]string
+	days, shortDays     [7]string // mulai Minggu, sama dengan time.Weekday
+}
+
+var locales = map[string]dateNames{
+	"en": {
+		months:      [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		shortMonths: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		days:        [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		shortDays:   [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	},
+	"id": {
+		months:      [12]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"},
+		shortMonths: [12]string{"Jan", "Feb", "Mar", "Apr", "Mei", "Jun", "Jul", "Agu", "Sep", "Okt", "Nov", "Des"},
+		days:        [7]string{"Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"},
+		shortDays:   [7]string{"Min", "Sen", "Sel", "Rab", "Kam", "Jum", "Sab"},
+	},
+}
+
+// penanda nama bulan dan hari di layout, rune private use tidak dikenali oleh time.Format
+const (
+	markMonth      = "\ue000"
+	markShortMonth = "\ue001"
+	markDay        = "\ue002"
+	markShortDay   = "\ue003"
+)
+
+// formatDate seperti time.Format, tetapi nama bulan dan hari diganti sesuai locale
+func formatDate(t time.Time, layout, locale string) string {
+	names, ok := locales[locale]
+	if !ok {
+		names = locales["en"]
+	}
+
+	// token dikenali dengan aturan yang sama seperti time.Format: "Jan" dan "Mon" tidak boleh
+	// diikuti huruf kecil (mis. "Monument" tetap literal)
+	var sb strings.Builder
+	for i := 0; i < len(layout); {
+		rest := layout[i:]
+		switch {
+		case strings.HasPrefix(rest, "January"):
+			sb.WriteString(markMonth)
+			i += len("January")
+		case strings.HasPrefix(rest, "Monday"):
+			sb.WriteString(markDay)
+			i += len("Monday")
+		case strings.HasPrefix(rest, "Jan") && !startsWithLower(rest[3:]):
+			sb.WriteString(markShortMonth)
+			i += 3
+		case strings.HasPrefix(rest, "Mon") && !startsWithLower(rest[3:]):
+			sb.WriteString(markShortDay)
+			i += 3
+		default:
+			sb.WriteByte(layout[i])
+			i++
+		}
+	}
+
+	return strings.NewReplacer(
+		markMonth, names.months[t.Month()-1],
+		markShortMonth, names.shortMonths[t.Month()-1],
+		markDay, names.days[t.Weekday()],
+		markShortDay, names.shortDays[t.Weekday()],
+	).Replace(t.Format(sb.String()))
+}
+
+func startsWithLower(s string) bool {
+	return s != "" && s[0] >= 'a' && s[0] <= 'z'
+}
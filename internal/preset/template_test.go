@@ -0,0 +1,53 @@
+package preset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplate_Execute(t *testing.T) {
+	t.Setenv("MEMECRAFT_TPL_BRAND", "@kabarmeme")
+	t.Setenv("SECRET_KEY", "do-not-leak")
+	now := time.Date(2024, time.August, 5, 14, 30, 0, 0, time.UTC) // Senin
+
+	cases := []struct {
+		tmpl   string
+		locale string
+		want   string
+	}{
+		{"{{date:02 Jan 2006}}", "en", "05 Aug 2024"},
+		{"{{date:Monday, 2 January 2006}}", "id", "Senin, 5 Agustus 2024"},
+		{"{{ upper date:Mon 02/01 }}", "id", "SEN 05/08"},
+		{"Monument {{date:15:04}}", "en", "Monument 14:30"},
+		{"{{upper .headline}}!", "en", "BREAKING!"},
+		{"{{lower .missing}}-", "en", "-"},
+		{"via {{env.BRAND}}", "en", "via @kabarmeme"},
+		{"[{{env.SECRET_KEY}}]", "en", "[]"}, // hanya variable berprefix MEMECRAFT_TPL_
+		{"[{{env.MEMECRAFT_TPL_BRAND}}]", "en", "[]"},
+		{"tanpa placeholder", "en", "tanpa placeholder"},
+	}
+	for _, c := range cases {
+		tmpl, err := ParseTemplate(c.tmpl)
+		require.NoError(t, err, c.tmpl)
+		assert.Equal(t, c.want, tmpl.Execute(map[string]string{"headline": "breaking"}, now, c.locale), c.tmpl)
+	}
+
+	// teks dari request tidak ikut dievaluasi
+	tmpl, err := ParseTemplate("{{.headline}}")
+	require.NoError(t, err)
+	assert.Equal(t, "{{env.BRAND}}", tmpl.Execute(map[string]string{"headline": "{{env.BRAND}}"}, now, "en"))
+}
+
+func TestParseTemplate_Invalid(t *testing.T) {
+	for _, s := range []string{"{{date:02 Jan", "{{date:}}", "{{env.A-B}}", "{{headline}}", "{{upper}}", "{{. }}"} {
+		_, err := ParseTemplate(s)
+		assert.Error(t, err, s)
+	}
+
+	tmpl, err := ParseTemplate("{{.a}} {{upper .b}} {{env.X}}")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, tmpl.Fields())
+}
@@ -0,0 +1,268 @@
+package imageutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"time"
+)
+
+var ErrAnimationTooLong = errors.New("animation too long")
+
+// Animation frame GIF yang sudah dikomposisi penuh (bukan delta antar frame)
+type Animation struct {
+	Frames    []image.Image
+	Delays    []int // per frame, dalam 1/100 detik
+	LoopCount int   // 0 = loop terus, -1 = sekali main
+}
+
+// AnimationLimits batas jumlah frame, durasi dan total pixel GIF animasi, 0 = tidak dibatasi.
+// MaxTotalPixels = frame x lebar x tinggi layar GIF, karena setiap frame didecode lalu disalin
+// ke kanvas seukuran layar. Batas yang sama berlaku untuk frame hasil render (lihat CheckFrames).
+type AnimationLimits struct {
+	MaxFrames      int
+	MaxDuration    time.Duration
+	MaxTotalPixels int
+}
+
+var DefaultAnimationLimits = AnimationLimits{
+	MaxFrames:      100,
+	MaxDuration:    20 * time.Second,
+	MaxTotalPixels: 50_000_000,
+}
+
+// browser memperlakukan delay < 2 (1/100 detik) sebagai 10
+const minEffectiveGIFDelay = 2
+
+// DecodeAnimation decode gambar menjadi daftar frame. Selain GIF (atau GIF satu frame)
+// hasilnya satu frame. Jumlah frame, durasi dan total pixel dicek dari struktur GIF sebelum LZW didecode.
+func DecodeAnimation(data []byte, limits DecodeLimits, animLimits AnimationLimits) (*Animation, error) {
+	cfg, format, err := CheckLimits(data, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	if format != "gif" {
+		img, err := Decode(data, limits)
+		if err != nil {
+			return nil, err
+		}
+		return &Animation{Frames: []image.Image{img}, Delays: []int{0}}, nil
+	}
+
+	if err := CheckAnimationLimits(data, animLimits); err != nil {
+		return nil, err
+	}
+
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if len(g.Image) == 0 {
+		return nil, fmt.Errorf("%w: gif has no frames", ErrInvalidImage)
+	}
+
+	return &Animation{
+		Frames:    flattenGIF(g, cfg.Width, cfg.Height),
+		Delays:    g.Delay,
+		LoopCount: g.LoopCount,
+	}, nil
+}
+
+// CheckAnimationLimits mengecek jumlah frame, durasi dan total pixel GIF dari struktur bloknya saja,
+// tanpa decode LZW. Gambar selain GIF selalu lolos, limits kosong = DefaultAnimationLimits.
+func CheckAnimationLimits(data []byte, limits AnimationLimits) error {
+	if !bytes.HasPrefix(data, []byte("GIF8")) {
+		return nil
+	}
+	if limits == (AnimationLimits{}) {
+		limits = DefaultAnimationLimits
+	}
+
+	info, err := scanGIF(data)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if limits.MaxFrames > 0 && info.frames > limits.MaxFrames {
+		return fmt.Errorf("%w: %d frames exceeds %d", ErrAnimationTooLong, info.frames, limits.MaxFrames)
+	}
+	if limits.MaxDuration > 0 && info.duration > limits.MaxDuration {
+		return fmt.Errorf("%w: %s exceeds %s", ErrAnimationTooLong, info.duration, limits.MaxDuration)
+	}
+	return limits.CheckFrames(info.frames, info.width, info.height)
+}
+
+// CheckFrames mengecek frame x lebar x tinggi terhadap MaxTotalPixels. Dipakai juga sebelum
+// render animasi, karena setiap frame hasil adalah kanvas RGBA seukuran base image walaupun
+// GIF inputnya kecil. limits kosong = DefaultAnimationLimits.
+func (l AnimationLimits) CheckFrames(frames, width, height int) error {
+	if l == (AnimationLimits{}) {
+		l = DefaultAnimationLimits
+	}
+	if pixels := frames * width * height; l.MaxTotalPixels > 0 && pixels > l.MaxTotalPixels {
+		return fmt.Errorf("%w: %d frames of %dx%d exceeds %d total pixels", ErrAnimationTooLong, frames, width, height, l.MaxTotalPixels)
+	}
+	return nil
+}
+
+// EncodeAnimation encode frame menjadi GIF animasi, tiap frame memakai palet hasil median cut sendiri
+func EncodeAnimation(frames []image.Image, delays []int, loopCount int) ([]byte, error) {
+	g := &gif.GIF{
+		Image:     make([]*image.Paletted, len(frames)),
+		Delay:     make([]int, len(frames)),
+		LoopCount: loopCount,
+	}
+	for i, frame := range frames {
+		g.Image[i] = Quantize(frame, 256)
+		if i < len(delays) {
+			g.Delay[i] = delays[i]
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// flattenGIF menerapkan disposal method tiap frame sehingga setiap frame berisi gambar utuh
+func flattenGIF(g *gif.GIF, width, height int) []image.Image {
+	canvas := NewCanvas(width, height)
+	frames := make([]image.Image, 0, len(g.Image))
+
+	for i, frame := range g.Image {
+		disposal := byte(0)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+
+		var previous *image.RGBA
+		if disposal == gif.DisposalPrevious {
+			previous = cloneRGBA(canvas)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		frames = append(frames, cloneRGBA(canvas))
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+
+	return frames
+}
+
+func cloneRGBA(src *image.RGBA) *image.RGBA {
+	dst := image.NewRGBA(src.Rect)
+	copy(dst.Pix, src.Pix)
+	return dst
+}
+
+// gifInfo hasil scanGIF, width dan height ukuran layar (logical screen) GIF
+type gifInfo struct {
+	frames        int
+	duration      time.Duration
+	width, height int
+}
+
+// scanGIF menghitung jumlah frame dan total durasi dengan membaca blok GIF tanpa decode LZW
+func scanGIF(data []byte) (gifInfo, error) {
+	r := &byteReader{data: data}
+
+	header := r.next(13) // signature + logical screen descriptor
+	if header == nil || string(header[:3]) != "GIF" {
+		return gifInfo{}, errors.New("invalid gif header")
+	}
+	info := gifInfo{
+		width:  int(header[6]) | int(header[7])<<8,
+		height: int(header[8]) | int(header[9])<<8,
+	}
+	if header[10]&0x80 != 0 {
+		r.next(3 << (header[10]&0x07 + 1)) // global color table
+	}
+
+	delay, totalDelay := 0, 0
+	for {
+		b := r.next(1)
+		if b == nil {
+			return gifInfo{}, errors.New("unexpected end of gif")
+		}
+
+		switch b[0] {
+		case 0x21: // extension
+			label := r.next(1)
+			if label == nil {
+				return gifInfo{}, errors.New("unexpected end of gif")
+			}
+			if label[0] == 0xF9 { // graphic control extension
+				gce := r.next(6)
+				if gce == nil || gce[0] != 4 {
+					return gifInfo{}, errors.New("invalid graphic control extension")
+				}
+				delay = int(gce[2]) | int(gce[3])<<8
+				continue
+			}
+			if !r.skipSubBlocks() {
+				return gifInfo{}, errors.New("unexpected end of gif")
+			}
+		case 0x2C: // image descriptor
+			desc := r.next(9)
+			if desc == nil {
+				return gifInfo{}, errors.New("unexpected end of gif")
+			}
+			if desc[8]&0x80 != 0 {
+				r.next(3 << (desc[8]&0x07 + 1)) // local color table
+			}
+			if r.next(1) == nil || !r.skipSubBlocks() { // LZW minimum code size + data
+				return gifInfo{}, errors.New("unexpected end of gif")
+			}
+
+			info.frames++
+			totalDelay += max(delay, minEffectiveGIFDelay)
+			delay = 0
+		case 0x3B: // trailer
+			info.duration = time.Duration(totalDelay) * 10 * time.Millisecond
+			return info, nil
+		default:
+			return gifInfo{}, fmt.Errorf("unknown gif block 0x%02x", b[0])
+		}
+	}
+}
+
+type byteReader struct {
+	data []byte
+	pos  int
+}
+
+// next mengembalikan n byte berikutnya, nil bila data habis
+func (r *byteReader) next(n int) []byte {
+	if r.pos+n > len(r.data) {
+		r.pos = len(r.data)
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *byteReader) skipSubBlocks() bool {
+	for {
+		size := r.next(1)
+		if size == nil {
+			return false
+		}
+		if size[0] == 0 {
+			return true
+		}
+		if r.next(int(size[0])) == nil {
+			return false
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package imageutil
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generateGIF GIF animasi w x h, tiap frame hanya menggambar kotak merah kecil yang bergeser
+// (frame delta) dengan disposal none
+func generateGIF(t testing.TB, w, h, frames, delay int) []byte {
+	palette := color.Palette{color.Transparent, color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}}
+	g := &gif.GIF{LoopCount: 3, Config: image.Config{Width: w, Height: h, ColorModel: palette}}
+
+	for i := 0; i < frames; i++ {
+		var frame *image.Paletted
+		if i == 0 {
+			frame = image.NewPaletted(image.Rect(0, 0, w, h), palette)
+			for p := range frame.Pix {
+				frame.Pix[p] = 2 // background biru penuh
+			}
+		} else {
+			x := i % (w - 2)
+			frame = image.NewPaletted(image.Rect(x, 0, x+2, 2), palette)
+			for p := range frame.Pix {
+				frame.Pix[p] = 1
+			}
+		}
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, delay)
+		g.Disposal = append(g.Disposal, gif.DisposalNone)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, g))
+	return buf.Bytes()
+}
+
+func TestDecodeAnimation_FlattensDeltaFrames(t *testing.T) {
+	anim, err := DecodeAnimation(generateGIF(t, 8, 8, 4, 7), DecodeLimits{}, AnimationLimits{})
+	require.NoError(t, err)
+
+	require.Len(t, anim.Frames, 4)
+	assert.Equal(t, []int{7, 7, 7, 7}, anim.Delays)
+	assert.Equal(t, 3, anim.LoopCount)
+
+	last := anim.Frames[3]
+	assert.Equal(t, image.Rect(0, 0, 8, 8), last.Bounds())
+	// kotak dari frame sebelumnya tetap ada karena disposal none, sisanya background frame pertama
+	for x := 1; x <= 4; x++ {
+		_, _, b, _ := last.At(x, 0).RGBA()
+		assert.Zero(t, b, "x=%d", x)
+	}
+	_, _, b, _ := last.At(7, 7).RGBA()
+	assert.Equal(t, uint32(0xffff), b)
+}
+
+func TestDecodeAnimation_StaticImageIsSingleFrame(t *testing.T) {
+	anim, err := DecodeAnimation(smallPNG(t), DecodeLimits{}, AnimationLimits{})
+	require.NoError(t, err)
+	assert.Len(t, anim.Frames, 1)
+}
+
+func TestCheckAnimationLimits(t *testing.T) {
+	data := generateGIF(t, 8, 8, 10, 50) // 10 frame x 0.5 detik
+
+	assert.NoError(t, CheckAnimationLimits(data, AnimationLimits{MaxFrames: 10, MaxDuration: 5 * time.Second}))
+	assert.ErrorIs(t, CheckAnimationLimits(data, AnimationLimits{MaxFrames: 9}), ErrAnimationTooLong)
+	assert.ErrorIs(t, CheckAnimationLimits(data, AnimationLimits{MaxDuration: 4 * time.Second}), ErrAnimationTooLong)
+	assert.ErrorIs(t, CheckAnimationLimits(data[:len(data)/2], AnimationLimits{}), ErrInvalidImage)
+	assert.NoError(t, CheckAnimationLimits(smallPNG(t), AnimationLimits{MaxFrames: 1}))
+
+	// total pixel dihitung dari ukuran layar, bukan ukuran frame delta yang kecil
+	assert.NoError(t, CheckAnimationLimits(data, AnimationLimits{MaxTotalPixels: 640}))
+	assert.ErrorIs(t, CheckAnimationLimits(data, AnimationLimits{MaxTotalPixels: 639}), ErrAnimationTooLong)
+
+	_, err := DecodeAnimation(data, DecodeLimits{}, AnimationLimits{MaxFrames: 5})
+	assert.ErrorIs(t, err, ErrAnimationTooLong)
+}
+
+func TestEncodeAnimation_PreservesTiming(t *testing.T) {
+	anim, err := DecodeAnimation(generateGIF(t, 8, 8, 3, 12), DecodeLimits{}, AnimationLimits{})
+	require.NoError(t, err)
+
+	data, err := EncodeAnimation(anim.Frames, anim.Delays, anim.LoopCount)
+	require.NoError(t, err)
+
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Len(t, g.Image, 3)
+	assert.Equal(t, []int{12, 12, 12}, g.Delay)
+	assert.Equal(t, 3, g.LoopCount)
+
+	r, _, b, _ := g.Image[2].At(2, 1).RGBA()
+	assert.Equal(t, uint32(0xffff), r)
+	assert.Zero(t, b)
+}
+
+func TestQuantize_KeepsDistinctColors(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 1))
+	colors := []color.RGBA{{255, 0, 0, 255}, {0, 255, 0, 255}, {0, 0, 255, 255}, {255, 255, 255, 255}}
+	for x, c := range colors {
+		img.SetRGBA(x, 0, c)
+	}
+
+	q := Quantize(img, 256)
+	assert.Len(t, q.Palette, 4)
+	for x, c := range colors {
+		assert.Equal(t, c, q.At(x, 0), "x=%d", x)
+	}
+}
@@ -0,0 +1,99 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pixel(c color.Color) *image.RGBA {
+	img := NewCanvas(1, 1)
+	img.Set(0, 0, c)
+	return img
+}
+
+func TestComposite_Modes(t *testing.T) {
+	// backdrop (0.8, 0.4, 0.2) dan source (0.2, 0.6, 1.0), keduanya opaque
+	backdrop := color.RGBA{R: 204, G: 102, B: 51, A: 255}
+	source := color.NRGBA{R: 51, G: 153, B: 255, A: 255}
+
+	cases := []struct {
+		mode BlendMode
+		want color.RGBA
+	}{
+		{BlendNormal, color.RGBA{R: 51, G: 153, B: 255, A: 255}},
+		{BlendMultiply, color.RGBA{R: 41, G: 61, B: 51, A: 255}},
+		{BlendScreen, color.RGBA{R: 214, G: 194, B: 255, A: 255}},
+		{BlendOverlay, color.RGBA{R: 173, G: 122, B: 102, A: 255}},
+		{BlendDarken, color.RGBA{R: 51, G: 102, B: 51, A: 255}},
+		{BlendLighten, color.RGBA{R: 204, G: 153, B: 255, A: 255}},
+		{BlendSoftLight, color.RGBA{R: 180, G: 114, B: 114, A: 255}},
+		{BlendDifference, color.RGBA{R: 153, G: 51, B: 204, A: 255}},
+	}
+	for _, c := range cases {
+		dst := pixel(backdrop)
+		Composite(dst, pixel(source), 0, 0, c.mode, 1)
+		assert.Equal(t, c.want, dst.RGBAAt(0, 0), string(c.mode))
+	}
+}
+
+func TestComposite_Transparency(t *testing.T) {
+	// backdrop alpha 0.8 premultiplied (warna asli 0.5, 0.25, 0), source alpha 0.4 dengan opacity
+	// 0.5 sehingga alpha efektifnya 0.2
+	dst := pixel(color.RGBA{R: 102, G: 51, B: 0, A: 204})
+	src := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	src.SetNRGBA(0, 0, color.NRGBA{R: 255, G: 102, B: 0, A: 102})
+	Composite(dst, src, 0, 0, BlendMultiply, 0.5)
+	assert.Equal(t, color.RGBA{R: 112, G: 49, B: 0, A: 214}, dst.RGBAAt(0, 0))
+
+	// backdrop transparan: blend tidak berpengaruh, source digambar dengan opacity-nya
+	dst = NewCanvas(1, 1)
+	Composite(dst, pixel(color.RGBA{R: 255, A: 255}), 0, 0, BlendDifference, 0.5)
+	assert.Equal(t, color.RGBA{R: 128, A: 128}, dst.RGBAAt(0, 0))
+
+	// source transparan tidak mengubah backdrop
+	dst = pixel(color.RGBA{R: 10, G: 20, B: 30, A: 255})
+	Composite(dst, NewCanvas(1, 1), 0, 0, BlendScreen, 1)
+	assert.Equal(t, color.RGBA{R: 10, G: 20, B: 30, A: 255}, dst.RGBAAt(0, 0))
+
+	// opacity 0 tidak menggambar apa pun
+	Composite(dst, pixel(color.RGBA{R: 255, A: 255}), 0, 0, BlendMultiply, 0)
+	assert.Equal(t, color.RGBA{R: 10, G: 20, B: 30, A: 255}, dst.RGBAAt(0, 0))
+}
+
+func TestComposite_NormalOpacity(t *testing.T) {
+	dst := pixel(color.RGBA{R: 204, G: 102, B: 51, A: 255})
+	Composite(dst, pixel(color.RGBA{R: 51, G: 153, B: 255, A: 255}), 0, 0, BlendNormal, 0.5)
+	got := dst.RGBAAt(0, 0)
+	assert.InDelta(t, 127, got.R, 1)
+	assert.InDelta(t, 128, got.G, 1)
+	assert.InDelta(t, 153, got.B, 1)
+	assert.Equal(t, uint8(255), got.A)
+}
+
+func TestComposite_Offset(t *testing.T) {
+	// source sebagian di luar kanvas hanya digambar di area yang beririsan
+	dst := NewCanvas(2, 1)
+	src := NewCanvas(2, 1)
+	src.SetRGBA(0, 0, color.RGBA{R: 255, A: 255})
+	src.SetRGBA(1, 0, color.RGBA{G: 255, A: 255})
+	Composite(dst, src, -1, 0, BlendMultiply, 1)
+	assert.Equal(t, color.RGBA{G: 255, A: 255}, dst.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{}, dst.RGBAAt(1, 0))
+}
+
+func TestParseBlendMode(t *testing.T) {
+	mode, err := ParseBlendMode("")
+	require.NoError(t, err)
+	assert.Equal(t, BlendNormal, mode)
+
+	mode, err = ParseBlendMode("soft-light")
+	require.NoError(t, err)
+	assert.Equal(t, BlendSoftLight, mode)
+
+	_, err = ParseBlendMode("burn")
+	assert.Error(t, err)
+}
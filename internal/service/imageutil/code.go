@@ -0,0 +1,159 @@
+package imageutil
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/qr"
+)
+
+type CodeFormat string
+
+const (
+	CodeQR      CodeFormat = "qr"
+	CodeCode128 CodeFormat = "code128"
+	CodeEAN     CodeFormat = "ean" // EAN-8 atau EAN-13 dari jumlah digit, checksum boleh tidak ditulis
+)
+
+// maxCodeContent batas isi kode dari request, QR versi 40 pun hanya muat sekitar 2900 byte
+const maxCodeContent = 1024
+
+// Code QR code atau barcode yang digambar di area Width x Height
+type Code struct {
+	Format          CodeFormat
+	Content         string
+	Width           int
+	Height          int
+	Foreground      string // kosong = hitam
+	Background      string // kosong = putih, "transparent" untuk tanpa background
+	ErrorCorrection string // khusus QR: "L", "M" (default), "Q", "H"
+}
+
+// Validate memeriksa pengaturan kode dari preset, isi kode diperiksa saat digambar
+func (c *Code) Validate() error {
+	switch c.Format {
+	case "", CodeQR, CodeCode128, CodeEAN:
+	default:
+		return fmt.Errorf("invalid code format %q", c.Format)
+	}
+	if _, err := c.level(); err != nil {
+		return err
+	}
+	for _, s := range []string{c.Foreground, c.Background} {
+		if s == "" {
+			continue
+		}
+		if _, err := ParseColor(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Code) level() (qr.ErrorCorrectionLevel, error) {
+	switch strings.ToUpper(c.ErrorCorrection) {
+	case "L":
+		return qr.L, nil
+	case "", "M":
+		return qr.M, nil
+	case "Q":
+		return qr.Q, nil
+	case "H":
+		return qr.H, nil
+	}
+	return 0, fmt.Errorf("invalid error correction level %q", c.ErrorCorrection)
+}
+
+func (c *Code) encode() (barcode.Barcode, error) {
+	if c.Content == "" {
+		return nil, errors.New("code content is empty")
+	}
+	if len(c.Content) > maxCodeContent {
+		return nil, fmt.Errorf("code content too long (max %d bytes)", maxCodeContent)
+	}
+
+	switch c.Format {
+	case CodeCode128:
+		return code128.Encode(c.Content)
+	case CodeEAN:
+		return ean.Encode(c.Content)
+	default:
+		level, err := c.level()
+		if err != nil {
+			return nil, err
+		}
+		return qr.Encode(c.Content, level, qr.Auto)
+	}
+}
+
+// DrawCode menggambar kode seukuran Width x Height. Modul selalu berukuran pixel bulat supaya
+// tajam dan mudah dipindai, sisa area menjadi background di sekeliling kode.
+func DrawCode(c Code) (image.Image, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	code, err := c.encode()
+	if err != nil {
+		// isi kode tidak ikut di pesan karena error ini bisa sampai ke log dan respons
+		format := c.Format
+		if format == "" {
+			format = CodeQR
+		}
+		return nil, fmt.Errorf("encode %s: %w", format, err)
+	}
+
+	fg, bg := color.Color(color.Black), color.Color(color.White)
+	if c.Foreground != "" {
+		fg, _ = ParseColor(c.Foreground)
+	}
+	if c.Background != "" {
+		bg, _ = ParseColor(c.Background)
+	}
+
+	// quiet zone minimal sesuai standar: 4 modul untuk QR, 10 modul untuk barcode
+	cb := code.Bounds()
+	quiet := 4
+	if code.Metadata().Dimensions == 1 {
+		quiet = 10
+	}
+	moduleW := c.Width / (cb.Dx() + 2*quiet)
+	moduleH := moduleW
+	codeH := cb.Dy() * moduleH
+	if code.Metadata().Dimensions == 1 {
+		// barcode 1D setinggi area, dikurangi quiet zone atas bawah yang sama dengan kiri kanan
+		codeH = c.Height - 2*quiet*moduleW
+	} else if h := c.Height / (cb.Dy() + 2*quiet); h < moduleW {
+		moduleW, moduleH = h, h
+		codeH = cb.Dy() * moduleH
+	}
+	if moduleW < 1 || codeH < 1 {
+		return nil, fmt.Errorf("%dx%d is too small for this code", c.Width, c.Height)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, c.Width, c.Height))
+	draw.Draw(img, img.Rect, image.NewUniform(bg), image.Point{}, draw.Src)
+
+	codeW := cb.Dx() * moduleW
+	ox, oy := (c.Width-codeW)/2, (c.Height-codeH)/2
+	fill := image.NewUniform(fg)
+	for y := 0; y < cb.Dy(); y++ {
+		for x := 0; x < cb.Dx(); x++ {
+			if r, _, _, _ := code.At(cb.Min.X+x, cb.Min.Y+y).RGBA(); r != 0 {
+				continue
+			}
+			r := image.Rect(ox+x*moduleW, oy+y*moduleH, ox+(x+1)*moduleW, oy+(y+1)*moduleH)
+			if code.Metadata().Dimensions == 1 {
+				r.Max.Y = oy + codeH
+			}
+			draw.Draw(img, r, fill, image.Point{}, draw.Src)
+		}
+	}
+	return img, nil
+}
@@ -0,0 +1,64 @@
+package imageutil
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrawCode_QR(t *testing.T) {
+	c := Code{Content: "https://example.com/artikel/123", Width: 300, Height: 200, Foreground: "#003366", Background: "#ffff00"}
+	img, err := DrawCode(c)
+	require.NoError(t, err)
+	assert.Equal(t, 300, img.Bounds().Dx())
+	assert.Equal(t, 200, img.Bounds().Dy())
+
+	code, err := c.encode()
+	require.NoError(t, err)
+	size := code.Bounds().Dx()
+	module := 200 / (size + 8) // dibatasi tinggi
+	ox, oy := (300-size*module)/2, (200-size*module)/2
+
+	// setiap modul digambar tepat, dicek di titik tengahnya
+	fg, bg := color.RGBA{R: 0x00, G: 0x33, B: 0x66, A: 0xff}, color.RGBA{R: 0xff, G: 0xff, A: 0xff}
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			want := bg
+			if r, _, _, _ := code.At(x, y).RGBA(); r == 0 {
+				want = fg
+			}
+			require.Equal(t, want, img.At(ox+x*module+module/2, oy+y*module+module/2), "module %d,%d", x, y)
+		}
+	}
+	// quiet zone
+	assert.Equal(t, bg, img.At(ox-1, oy))
+}
+
+func TestDrawCode_Barcode(t *testing.T) {
+	img, err := DrawCode(Code{Format: CodeCode128, Content: "MEME-2024", Width: 400, Height: 120})
+	require.NoError(t, err)
+
+	// bar pertama setelah quiet zone hitam dari atas sampai bawah area kode
+	var first int
+	for first = 0; first < 400; first++ {
+		if img.At(first, 60) == (color.RGBA{A: 0xff}) {
+			break
+		}
+	}
+	require.Less(t, first, 400)
+	assert.Equal(t, color.RGBA{A: 0xff}, img.At(first, 40))
+	assert.Equal(t, color.RGBA{A: 0xff}, img.At(first, 80))
+	assert.Equal(t, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, img.At(first, 2))
+
+	_, err = DrawCode(Code{Format: CodeEAN, Content: "abc", Width: 400, Height: 120})
+	assert.ErrorContains(t, err, "encode ean")
+	assert.NotContains(t, err.Error(), "abc")
+	_, err = DrawCode(Code{Format: CodeEAN, Content: "4006381333931", Width: 400, Height: 120})
+	assert.NoError(t, err)
+	_, err = DrawCode(Code{Content: "https://example.com", Width: 20, Height: 20})
+	assert.ErrorContains(t, err, "too small")
+	assert.Error(t, (&Code{Format: "pdf417"}).Validate())
+	assert.Error(t, (&Code{ErrorCorrection: "X"}).Validate())
+}
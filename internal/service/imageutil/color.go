@@ -0,0 +1,206 @@
+package imageutil
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/colornames"
+)
+
+// ParseColor membaca warna CSS: #RGB, #RGBA, #RRGGBB, #RRGGBBAA (tanda # boleh tidak ditulis
+// untuk 6 dan 8 digit), nama warna CSS, transparent, rgb()/rgba() dan hsl()/hsla().
+// Hasilnya color.RGBA (premultiplied) supaya bisa langsung dipakai untuk menggambar.
+func ParseColor(s string) (color.Color, error) {
+	s = strings.TrimSpace(s)
+	c, err := parseColor(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color %q: %w", s, err)
+	}
+	return color.RGBAModel.Convert(c), nil
+}
+
+func parseColor(s string) (color.NRGBA, error) {
+	lower := strings.ToLower(s)
+	if name, args, ok := parseFunc(lower); ok {
+		switch name {
+		case "rgb", "rgba":
+			return parseRGBFunc(args)
+		case "hsl", "hsla":
+			return parseHSLFunc(args)
+		}
+		return color.NRGBA{}, fmt.Errorf("unknown function %s()", name)
+	}
+
+	switch lower {
+	case "transparent":
+		return color.NRGBA{}, nil
+	case "rebeccapurple":
+		// CSS Color 4, belum ada di colornames (SVG 1.1)
+		return color.NRGBA{R: 0x66, G: 0x33, B: 0x99, A: 0xff}, nil
+	}
+	if c, ok := colornames.Map[lower]; ok {
+		return color.NRGBA{R: c.R, G: c.G, B: c.B, A: c.A}, nil
+	}
+
+	hex, hash := strings.CutPrefix(lower, "#")
+	switch {
+	case len(hex) == 6 || len(hex) == 8:
+	case hash && (len(hex) == 3 || len(hex) == 4):
+		// shorthand, setiap digit diulang: #f80 = #ff8800
+		var long strings.Builder
+		for _, r := range hex {
+			long.WriteRune(r)
+			long.WriteRune(r)
+		}
+		hex = long.String()
+	default:
+		return color.NRGBA{}, fmt.Errorf("unknown color")
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid hex digits")
+	}
+	if len(hex) == 6 {
+		v = v<<8 | 0xff
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// colorArgs memecah argumen rgb()/hsl() dengan koma atau spasi, alpha boleh dipisah dengan "/"
+func colorArgs(args string) ([]string, string, error) {
+	main, alpha, hasAlpha := strings.Cut(args, "/")
+	var parts []string
+	if strings.Contains(main, ",") {
+		parts = splitArgs(main)
+	} else {
+		parts = strings.Fields(main)
+	}
+	if hasAlpha {
+		if len(parts) != 3 {
+			return nil, "", fmt.Errorf("expected 3 values before /")
+		}
+		return parts, strings.TrimSpace(alpha), nil
+	}
+	switch len(parts) {
+	case 3:
+		return parts, "", nil
+	case 4:
+		return parts[:3], parts[3], nil
+	}
+	return nil, "", fmt.Errorf("expected 3 or 4 values")
+}
+
+// parseNumber membaca angka atau persen, persen dikali scale/100
+func parseNumber(s string, scale float64) (float64, error) {
+	s = strings.TrimSpace(s)
+	if p, ok := strings.CutSuffix(s, "%"); ok {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", s)
+		}
+		return v * scale / 100, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return v, nil
+}
+
+func parseAlpha(s string) (uint8, error) {
+	if s == "" {
+		return 255, nil
+	}
+	a, err := parseNumber(s, 1)
+	if err != nil {
+		return 0, err
+	}
+	if a < 0 || a > 1 {
+		return 0, fmt.Errorf("alpha out of range: %s", s)
+	}
+	return uint8(math.Round(a * 255)), nil
+}
+
+func parseRGBFunc(args string) (color.NRGBA, error) {
+	parts, alpha, err := colorArgs(args)
+	if err != nil {
+		return color.NRGBA{}, err
+	}
+	var rgb [3]uint8
+	for i, p := range parts {
+		v, err := parseNumber(p, 255)
+		if err != nil {
+			return color.NRGBA{}, err
+		}
+		if v < 0 || v > 255 {
+			return color.NRGBA{}, fmt.Errorf("value out of range: %s", p)
+		}
+		rgb[i] = uint8(math.Round(v))
+	}
+	a, err := parseAlpha(alpha)
+	if err != nil {
+		return color.NRGBA{}, err
+	}
+	return color.NRGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: a}, nil
+}
+
+func parseHSLFunc(args string) (color.NRGBA, error) {
+	parts, alpha, err := colorArgs(args)
+	if err != nil {
+		return color.NRGBA{}, err
+	}
+	h, err := parseNumber(strings.TrimSuffix(strings.TrimSpace(parts[0]), "deg"), 360)
+	if err != nil {
+		return color.NRGBA{}, err
+	}
+	var sl [2]float64
+	for i, p := range parts[1:] {
+		if !strings.HasSuffix(strings.TrimSpace(p), "%") {
+			return color.NRGBA{}, fmt.Errorf("saturation and lightness must be percentages")
+		}
+		v, err := parseNumber(p, 1)
+		if err != nil {
+			return color.NRGBA{}, err
+		}
+		if v < 0 || v > 1 {
+			return color.NRGBA{}, fmt.Errorf("value out of range: %s", p)
+		}
+		sl[i] = v
+	}
+	a, err := parseAlpha(alpha)
+	if err != nil {
+		return color.NRGBA{}, err
+	}
+
+	r, g, b := hslToRGB(math.Mod(math.Mod(h, 360)+360, 360)/360, sl[0], sl[1])
+	return color.NRGBA{R: r, G: g, B: b, A: a}, nil
+}
+
+// hslToRGB konversi HSL (semua 0..1) ke RGB
+func hslToRGB(h, s, l float64) (uint8, uint8, uint8) {
+	q := l + s - l*s
+	if l < 0.5 {
+		q = l * (1 + s)
+	}
+	p := 2*l - q
+	hue := func(t float64) uint8 {
+		t = math.Mod(t+1, 1)
+		var v float64
+		switch {
+		case t < 1.0/6:
+			v = p + (q-p)*6*t
+		case t < 1.0/2:
+			v = q
+		case t < 2.0/3:
+			v = p + (q-p)*(2.0/3-t)*6
+		default:
+			v = p
+		}
+		return uint8(math.Round(v * 255))
+	}
+	return hue(h + 1.0/3), hue(h), hue(h - 1.0/3)
+}
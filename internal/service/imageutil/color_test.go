@@ -0,0 +1,41 @@
+package imageutil
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want color.RGBA
+	}{
+		{"#ff8800", color.RGBA{R: 255, G: 136, A: 255}},
+		{"FF8800", color.RGBA{R: 255, G: 136, A: 255}},
+		{"#f80", color.RGBA{R: 255, G: 136, A: 255}},
+		{"#f808", color.RGBA{R: 136, G: 72, A: 136}}, // premultiplied
+		{"#ff000080", color.RGBA{R: 128, A: 128}},
+		{"Red", color.RGBA{R: 255, A: 255}},
+		{"rebeccapurple", color.RGBA{R: 102, G: 51, B: 153, A: 255}},
+		{"transparent", color.RGBA{}},
+		{"rgb(255, 136, 0)", color.RGBA{R: 255, G: 136, A: 255}},
+		{"rgb(100% 0% 0% / 50%)", color.RGBA{R: 128, A: 128}},
+		{"rgba(0, 0, 255, 0.5)", color.RGBA{B: 128, A: 128}},
+		{"hsl(120, 100%, 50%)", color.RGBA{G: 255, A: 255}},
+		{"hsl(240deg 100% 50%)", color.RGBA{B: 255, A: 255}},
+		{"hsla(0, 0%, 100%, 1)", color.RGBA{R: 255, G: 255, B: 255, A: 255}},
+	}
+	for _, tt := range tests {
+		c, err := ParseColor(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, c, tt.in)
+	}
+
+	for _, s := range []string{"", "#", "#ff", "#ff00f", "#gggggg", "ff0", "notacolor", "rgb(1, 2)", "rgb(300, 0, 0)", "rgba(0, 0, 0, 2)", "hsl(0, 50, 50)", "cmyk(0, 0, 0, 0)"} {
+		_, err := ParseColor(s)
+		assert.Error(t, err, s)
+	}
+}
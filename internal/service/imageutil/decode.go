@@ -0,0 +1,66 @@
+package imageutil
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/disintegration/imaging"
+)
+
+const reencodeJPEGQuality = 92
+
+// Decode decode gambar dari bytes dan memutarnya sesuai tag EXIF orientation (foto dari HP).
+// Dimensi dicek lebih dulu terhadap limits, limits kosong = DefaultDecodeLimits.
+func Decode(data []byte, limits DecodeLimits) (image.Image, error) {
+	if _, _, err := CheckLimits(data, limits); err != nil {
+		return nil, err
+	}
+	return imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+}
+
+// StripMetadata decode lalu encode ulang gambar JPEG/PNG/GIF supaya metadata (EXIF, GPS, komentar, dll) hilang.
+// Orientasi EXIF diterapkan ke pixel lebih dulu supaya hasilnya tidak miring.
+// Untuk GIF, jumlah frame, durasi dan total pixel sebaiknya dicek dulu dengan CheckAnimationLimits.
+func StripMetadata(data []byte, limits DecodeLimits) ([]byte, error) {
+	_, format, err := CheckLimits(data, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "gif" {
+		// semua frame dipertahankan, hanya extension selain loop count yang hilang
+		g, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+		var buf bytes.Buffer
+		if err := gif.EncodeAll(&buf, g); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: reencodeJPEGQuality})
+	case "png":
+		err = png.Encode(&buf, img)
+	default:
+		return nil, fmt.Errorf("%w: unsupported image format %s", ErrInvalidImage, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
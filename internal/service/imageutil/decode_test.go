@@ -0,0 +1,78 @@
+package imageutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generateExifJPEG membuat JPEG w x h dengan segmen APP1 Exif berisi orientation dan tag GPS palsu
+func generateExifJPEG(t *testing.T, w, h int, orientation uint16) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 100, 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+
+	// TIFF little endian, IFD0 berisi Orientation (0x0112) dan GPSInfo (0x8825)
+	tiff := new(bytes.Buffer)
+	tiff.WriteString("II")
+	_ = binary.Write(tiff, binary.LittleEndian, uint16(42))
+	_ = binary.Write(tiff, binary.LittleEndian, uint32(8))
+	_ = binary.Write(tiff, binary.LittleEndian, uint16(2))
+	_ = binary.Write(tiff, binary.LittleEndian, []uint16{0x0112, 3})
+	_ = binary.Write(tiff, binary.LittleEndian, uint32(1))
+	_ = binary.Write(tiff, binary.LittleEndian, []uint16{orientation, 0})
+	_ = binary.Write(tiff, binary.LittleEndian, []uint16{0x8825, 4})
+	_ = binary.Write(tiff, binary.LittleEndian, []uint32{1, 0})
+	_ = binary.Write(tiff, binary.LittleEndian, uint32(0))
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	src := buf.Bytes()
+	out := append([]byte{}, src[:2]...) // SOI
+	out = append(out, segment...)
+	return append(out, src[2:]...)
+}
+
+func TestDecode_AppliesExifOrientation(t *testing.T) {
+	data := generateExifJPEG(t, 60, 30, 6) // rotate 90 CW
+
+	img, err := Decode(data, DecodeLimits{})
+
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 30, 60), img.Bounds())
+}
+
+func TestStripMetadata_RemovesExif(t *testing.T) {
+	data := generateExifJPEG(t, 60, 30, 6)
+	require.True(t, bytes.Contains(data, []byte("Exif")))
+
+	stripped, err := StripMetadata(data, DecodeLimits{})
+
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(stripped, []byte("Exif")))
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(stripped))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, 30, cfg.Width)
+	assert.Equal(t, 60, cfg.Height)
+}
+
+func TestStripMetadata_RejectsNonImage(t *testing.T) {
+	_, err := StripMetadata([]byte("Hello, World!"), DecodeLimits{})
+	assert.ErrorIs(t, err, ErrInvalidImage)
+}
@@ -4,11 +4,16 @@ import (
 	"fmt"
 	"image"
 	"image/color"
-	"path/filepath"
-	"strconv"
+	"math"
 	"strings"
+	"unicode"
 
 	"github.com/fogleman/gg"
+	"github.com/go-text/typesetting/segmenter"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/f64"
+	"golang.org/x/image/math/fixed"
 )
 
 type TextBox struct {
@@ -18,19 +23,47 @@ type TextBox struct {
 	Width       int     `json:"width"`
 	Height      int     `json:"height"`
 	Font        string  `json:"font,omitempty"`
+	BoldFont    string  `json:"bold_font,omitempty"` // kosong = faux bold dari Font
 	Size        float64 `json:"size,omitempty"`
 	Color       string  `json:"color,omitempty"`
-	Align       string  `json:"align,omitempty"` // "left", "center", "right"
+	Align       string  `json:"align,omitempty"` // "left", "center", "right", "justify", kosong = mengikuti arah teks
 	LineSpacing float64 `json:"line_spacing,omitempty"`
+	Wrap        string  `json:"wrap,omitempty"`    // "word" (default), "char", "none"
 	Padding     int     `json:"padding,omitempty"` // jarak dari tepi
 	MaxChars    int     `json:"max_chars,omitempty"`
 	Normalize   string  `json:"normalize,omitempty"` // "normal", "toupper", "tolower"
+	Direction   string  `json:"direction,omitempty"` // "auto" (default), "ltr", "rtl"
+
+	LetterSpacing float64 `json:"letter_spacing,omitempty"` // pixel tambahan antar huruf, boleh negatif
+
+	FallbackFonts []string `json:"fallback_fonts,omitempty"` // dicoba berurutan bila huruf tidak ada di Font
+
+	Rotate float64 `json:"rotate,omitempty"` // derajat terhadap titik tengah box, arahnya sama dengan rotate overlay
+	SkewX  float64 `json:"skew_x,omitempty"` // derajat, positif = bagian atas miring ke kanan seperti italic
+
+	BackgroundColor   string  `json:"background_color,omitempty"`   // kosong = tanpa background
+	BackgroundPadding float64 `json:"background_padding,omitempty"` // jarak background dari teks
+	BackgroundRadius  float64 `json:"background_radius,omitempty"`
+	BackgroundMode    string  `json:"background_mode,omitempty"` // "line" (default, satu bar per baris) atau "block"
+}
+
+const (
+	TextBackgroundLine  = "line"
+	TextBackgroundBlock = "block"
+)
+
+// TextOptions pengaturan teks yang berlaku untuk semua text box
+type TextOptions struct {
+	FallbackFonts []string // dicoba setelah fallback_fonts milik box
+	EmojiDir      string   // folder gambar emoji berwarna (lihat emojiSet), kosong = emoji digambar dengan font
 }
 
-func DrawTextBoxes(base image.Image, text map[string]string, boxes []TextBox) (image.Image, error) {
+// DrawTextBoxes menggambar teks user ke setiap box. Teks boleh berisi markup (lihat ParseMarkup)
+// sehingga satu baris bisa berisi beberapa font dan warna.
+func DrawTextBoxes(base image.Image, text map[string]string, boxes []TextBox, opts TextOptions) (image.Image, error) {
 	bounds := base.Bounds()
-	dc := gg.NewContext(bounds.Dx(), bounds.Dy())
-	dc.DrawImage(base, 0, 0)
+	canvas := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(canvas, canvas.Bounds(), base, bounds.Min, draw.Src)
 
 	for _, box := range boxes {
 		userText, ok := text[box.Name]
@@ -38,36 +71,29 @@ func DrawTextBoxes(base image.Image, text map[string]string, boxes []TextBox) (i
 			continue
 		}
 
-		switch strings.ToLower(box.Normalize) {
-		case "tolower":
-			userText = strings.ToLower(userText)
-		case "toupper":
-			userText = strings.ToUpper(userText)
-			// "normal" atau lainnya -> no normalization
+		spans, err := ParseMarkup(userText)
+		if err != nil {
+			return nil, fmt.Errorf("text box %s: %w", box.Name, err)
+		}
+
+		for i := range spans {
+			switch strings.ToLower(box.Normalize) {
+			case "tolower":
+				spans[i].Text = strings.ToLower(spans[i].Text)
+			case "toupper":
+				spans[i].Text = strings.ToUpper(spans[i].Text)
+				// "normal" atau lainnya -> no normalization
+			}
 		}
 
-		if box.MaxChars > 0 && len(userText) > box.MaxChars {
-			userText = userText[:box.MaxChars]
+		if box.MaxChars > 0 {
+			spans = truncateSpans(spans, box.MaxChars)
 		}
 
 		fontSize := box.Size
 		if fontSize == 0 {
 			fontSize = 24
 		}
-		if err := dc.LoadFontFace(filepath.Clean(box.Font), fontSize); err != nil {
-			return nil, fmt.Errorf("failed to load font %s: %w", box.Font, err)
-		}
-
-		if box.Color != "" {
-			if c, err := hexToColor(box.Color); err == nil {
-				dc.SetColor(c)
-			} else {
-				dc.SetRGB(0, 0, 0)
-			}
-		} else {
-			dc.SetRGB(0, 0, 0)
-		}
-
 		padding := float64(box.Padding)
 		if padding < 0 {
 			padding = 0
@@ -82,69 +108,281 @@ func DrawTextBoxes(base image.Image, text map[string]string, boxes []TextBox) (i
 			continue
 		}
 
-		// text h-alignment
-		align := gg.AlignLeft
-		switch box.Align {
-		case "center":
-			align = gg.AlignCenter
-		case "right":
-			align = gg.AlignRight
+		// tanpa color teks berwarna hitam
+		fill := &Paint{kind: paintSolid, color: color.Black}
+		if box.Color != "" {
+			if fill, err = ParsePaint(box.Color); err != nil {
+				return nil, fmt.Errorf("text box %s: %w", box.Name, err)
+			}
+		}
+
+		face, err := loadFontFace(box.Font, fontSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load font %s: %w", box.Font, err)
+		}
+		pieces, err := styleSpans(box, spans, opts, face, fontSize, fill.source(effX, effY, effW, effH))
+		if err != nil {
+			return nil, err
+		}
+
+		lines := layoutParagraphs(pieces, box.Wrap, box.Direction, effW)
+
+		// tinggi baris dan posisi vertikal mengikuti gg.DrawStringWrapped (anchor di tengah box)
+		lineSpacing := box.LineSpacing
+		fontHeight := fontSize * 72 / 96
+		h := float64(len(lines))*fontHeight*lineSpacing - (lineSpacing-1)*fontHeight
+		y := effY + effH/2 - h/2
+
+		placed := make([]placedLine, len(lines))
+		for i, line := range lines {
+			// text h-alignment, tanpa align paragraf kanan ke kiri rata kanan
+			ax, x := 0.0, effX
+			switch {
+			case box.Align == "center":
+				ax, x = 0.5, effX+effW/2
+			case box.Align == "right", line.rtl && (box.Align == "" || box.Align == "justify"):
+				ax, x = 1, effX+effW
+			}
+
+			placed[i] = placedLine{line: line, x: x - ax*float64(line.width.Floor()), baseline: y + fontHeight}
+			if box.Align == "justify" {
+				if extra := justifySpace(line, effW); extra > 0 {
+					placed[i].x = effX
+					placed[i].spaceExtra = extra
+					placed[i].line.width = fixed.Int26_6(effW * 64)
+				}
+			}
+			y += fontHeight * lineSpacing
 		}
 
-		centerX := effX + effW/2
-		centerY := effY + effH/2
+		// background boleh keluar dari area padding, tapi tetap di dalam box. Box yang diputar
+		// digambar utuh dulu karena bagian di luar kanvas bisa masuk setelah diputar.
+		transformed := box.Rotate != 0 || box.SkewX != 0
+		boxRect := image.Rect(int(math.Floor(box.X)), int(math.Floor(box.Y)), int(math.Ceil(box.X+float64(box.Width))), int(math.Ceil(box.Y+float64(box.Height))))
+		if !transformed {
+			boxRect = boxRect.Intersect(canvas.Rect)
+		}
+		layer := image.NewRGBA(boxRect)
+		if box.BackgroundColor != "" {
+			if err := drawTextBackground(layer, box, placed, face.Metrics()); err != nil {
+				return nil, fmt.Errorf("text box %s: %w", box.Name, err)
+			}
+		}
+
+		// teks di luar area padding dipotong
+		clip := image.Rect(int(math.Floor(effX)), int(math.Floor(effY)), int(math.Ceil(effX+effW)), int(math.Ceil(effY+effH))).Intersect(boxRect)
+		textLayer := layer.SubImage(clip).(*image.RGBA)
+		for _, p := range placed {
+			drawLine(textLayer, p.line, fixed.Point26_6{X: fixed.Int26_6(p.x * 64), Y: fixed.Int26_6(p.baseline * 64)}, p.spaceExtra)
+		}
+
+		if transformed {
+			draw.BiLinear.Transform(canvas, boxTransform(box), layer, layer.Rect, draw.Over, nil)
+			continue
+		}
+		draw.Draw(canvas, boxRect, layer, boxRect.Min, draw.Over)
+	}
 
-		dc.Push()
-		dc.DrawRectangle(effX, effY, effW, effH)
-		dc.Clip()
+	return canvas, nil
+}
 
-		dc.DrawStringWrapped(
-			userText,
-			centerX,
-			centerY,
-			0.5,             // anchor X
-			0.5,             // anchor Y
-			effW,            // wrapping width
-			box.LineSpacing, // line spacing
-			align,
-		)
+// boxTransform matriks dari koordinat box ke kanvas: skew lalu rotasi terhadap titik tengah box.
+// Rotasi positif berlawanan jarum jam seperti imaging.Rotate yang dipakai untuk overlay.
+func boxTransform(box TextBox) f64.Aff3 {
+	sin, cos := math.Sincos(box.Rotate * math.Pi / 180)
+	skew := math.Tan(box.SkewX * math.Pi / 180)
+	cx, cy := box.X+float64(box.Width)/2, box.Y+float64(box.Height)/2
 
-		dc.Pop()
+	a, b := cos, sin-cos*skew
+	d, e := -sin, cos+sin*skew
+	return f64.Aff3{
+		a, b, cx - a*cx - b*cy,
+		d, e, cy - d*cx - e*cy,
 	}
+}
 
-	return dc.Image(), nil
+// placedLine baris teks beserta posisi awal (kiri) dan baseline-nya di kanvas
+type placedLine struct {
+	line       textLine
+	x          float64
+	baseline   float64
+	spaceExtra fixed.Int26_6 // tambahan lebar setiap deret spasi untuk align justify
 }
 
-// helper: hex string (#RRGGBB atau #RRGGBBAA) → color.Color
-func hexToColor(hex string) (color.Color, error) {
-	hex = strings.TrimPrefix(hex, "#")
+// drawTextBackground menggambar bar di belakang setiap baris (mode line) atau satu bar untuk
+// seluruh blok teks (mode block). Semua bar diisi sebagai satu path supaya bagian yang
+// bertumpuk tidak menjadi lebih gelap bila warnanya transparan.
+func drawTextBackground(layer *image.RGBA, box TextBox, lines []placedLine, metrics font.Metrics) error {
+	c, err := ParseColor(box.BackgroundColor)
+	if err != nil {
+		return fmt.Errorf("background: %w", err)
+	}
 
-	var r, g, b, a uint64
+	ascent, descent := float64(metrics.Ascent)/64, float64(metrics.Descent)/64
+	pad := box.BackgroundPadding
+
+	type rect struct{ x0, y0, x1, y1 float64 }
+	var rects []rect
+	for _, l := range lines {
+		if l.line.width == 0 {
+			continue
+		}
+		rects = append(rects, rect{
+			x0: l.x - pad,
+			y0: l.baseline - ascent - pad,
+			x1: l.x + float64(l.line.width.Floor()) + pad,
+			y1: l.baseline + descent + pad,
+		})
+	}
+	if len(rects) == 0 {
+		return nil
+	}
+
+	switch box.BackgroundMode {
+	case "", TextBackgroundLine:
+	case TextBackgroundBlock:
+		block := rects[0]
+		for _, r := range rects[1:] {
+			block.x0, block.x1 = math.Min(block.x0, r.x0), math.Max(block.x1, r.x1)
+			block.y0, block.y1 = math.Min(block.y0, r.y0), math.Max(block.y1, r.y1)
+		}
+		rects = []rect{block}
+	default:
+		return fmt.Errorf("invalid background mode: %s", box.BackgroundMode)
+	}
+
+	origin := layer.Rect.Min
+	dc := gg.NewContext(layer.Rect.Dx(), layer.Rect.Dy())
+	for _, r := range rects {
+		x, y := r.x0-float64(origin.X), r.y0-float64(origin.Y)
+		if box.BackgroundRadius > 0 {
+			dc.DrawRoundedRectangle(x, y, r.x1-r.x0, r.y1-r.y0, box.BackgroundRadius)
+		} else {
+			dc.DrawRectangle(x, y, r.x1-r.x0, r.y1-r.y0)
+		}
+	}
+	dc.SetColor(c)
+	dc.Fill()
+
+	draw.Draw(layer, layer.Rect, dc.Image(), image.Point{}, draw.Over)
+	return nil
+}
+
+// styleSpans memuat font dan warna untuk setiap span. Setiap grapheme memakai font pertama di
+// chain (Font, FallbackFonts box, lalu FallbackFonts global) yang punya hurufnya, dan emoji yang
+// ada di set emoji digambar sebagai gambar berwarna.
+func styleSpans(box TextBox, spans []TextSpan, opts TextOptions, face *fontFace, fontSize float64, fill image.Image) ([]textPiece, error) {
 	var err error
+	var boldFace *fontFace
+	if box.BoldFont != "" {
+		if boldFace, err = loadFontFace(box.BoldFont, fontSize); err != nil {
+			return nil, fmt.Errorf("failed to load font %s: %w", box.BoldFont, err)
+		}
+	}
+	fauxBold := max(1, int(math.Round(fontSize/32)))
 
-	switch len(hex) {
-	case 6: // RRGGBB
-		r, err = strconv.ParseUint(hex[0:2], 16, 8)
-		if err != nil {
-			return nil, err
+	chain := newFontChain(append(append([]string{box.Font}, box.FallbackFonts...), opts.FallbackFonts...), fontSize)
+	chain.faces[0] = face
+
+	var emojis *emojiSet
+	if opts.EmojiDir != "" {
+		if emojis, err = loadEmojiSet(opts.EmojiDir); err != nil {
+			return nil, fmt.Errorf("failed to load emoji set %s: %w", opts.EmojiDir, err)
 		}
-		g, err = strconv.ParseUint(hex[2:4], 16, 8)
-		if err != nil {
-			return nil, err
+	}
+
+	type styleKey struct {
+		face  *fontFace
+		bold  bool
+		color color.Color
+	}
+	styles := make(map[styleKey]*textStyle)
+	styleFor := func(span TextSpan, f *fontFace) *textStyle {
+		key := styleKey{face: f, bold: span.Bold, color: span.Color}
+		if style, ok := styles[key]; ok {
+			return style
 		}
-		b, err = strconv.ParseUint(hex[4:6], 16, 8)
-		if err != nil {
-			return nil, err
+		style := &textStyle{face: f, fill: fill, letterSpacing: fixed.Int26_6(box.LetterSpacing * 64)}
+		if span.Bold && f != boldFace {
+			style.boldness = fauxBold
+		}
+		if span.Color != nil {
+			style.fill = image.NewUniform(span.Color)
+		}
+		styles[key] = style
+		return style
+	}
+
+	var pieces []textPiece
+	// teks piece terakhir dikumpulkan di builder dan baru disalin saat piece ditutup
+	var buf strings.Builder
+	open := false
+	closePiece := func() {
+		if open {
+			pieces[len(pieces)-1].text = buf.String()
+			buf.Reset()
+			open = false
+		}
+	}
+
+	var seg segmenter.Segmenter
+	for _, span := range spans {
+		current := face // spasi dan karakter kontrol ikut font grapheme sebelumnya
+		if span.Bold && boldFace != nil {
+			current = boldFace
+		}
+
+		seg.Init([]rune(span.Text))
+		for iter := seg.GraphemeIterator(); iter.Next(); {
+			cluster := iter.Grapheme().Text
+			r := cluster[0]
+
+			key, isEmoji := "", false
+			if emojis != nil {
+				key, isEmoji = emojis.lookup(cluster)
+			}
+
+			f, found := current, true
+			if !isEmoji || !emojiPresentation(cluster) {
+				switch {
+				case unicode.IsSpace(r) || unicode.IsControl(r):
+				case span.Bold && boldFace != nil && boldFace.hasGlyph(r):
+					f = boldFace
+				default:
+					i, err := chain.faceFor(r)
+					if err != nil {
+						return nil, err
+					}
+					f, found = face, i >= 0
+					if found {
+						f = chain.faces[i]
+					}
+				}
+			}
+
+			if isEmoji && (emojiPresentation(cluster) || !found) {
+				img, err := emojis.image(key)
+				if err != nil {
+					return nil, err
+				}
+				closePiece()
+				pieces = append(pieces, textPiece{text: string(cluster), style: styleFor(span, face), emoji: img})
+				continue
+			}
+
+			current = f
+			style := styleFor(span, f)
+			if !open || pieces[len(pieces)-1].style != style {
+				closePiece()
+				pieces = append(pieces, textPiece{style: style})
+				open = true
+			}
+			for _, r := range cluster {
+				buf.WriteRune(r)
+			}
 		}
-		a = 255
-	case 8: // RRGGBBAA
-		r, _ = strconv.ParseUint(hex[0:2], 16, 8)
-		g, _ = strconv.ParseUint(hex[2:4], 16, 8)
-		b, _ = strconv.ParseUint(hex[4:6], 16, 8)
-		a, _ = strconv.ParseUint(hex[6:8], 16, 8)
-	default:
-		return nil, fmt.Errorf("invalid hex color: %s", hex)
 	}
+	closePiece()
 
-	return color.RGBA{uint8(r), uint8(g), uint8(b), uint8(a)}, nil
+	return pieces, nil
 }
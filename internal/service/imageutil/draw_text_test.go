@@ -0,0 +1,73 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrawTextBoxes_LineBackground(t *testing.T) {
+	box := TextBox{
+		Name: "headline", X: 0, Y: 0, Width: 400, Height: 200,
+		Font: testFont, Size: 40, LineSpacing: 3, Color: "#00000000", // teks transparan, hanya background yang terlihat
+		BackgroundColor: "#ff0000", BackgroundPadding: 4,
+	}
+	text := map[string]string{"headline": "long first line\nab"}
+
+	img, err := DrawTextBoxes(image.NewRGBA(image.Rect(0, 0, 400, 200)), text, []TextBox{box}, TextOptions{})
+	require.NoError(t, err)
+
+	red := color.RGBA{R: 255, A: 255}
+	// bar baris kedua hanya selebar "ab", bar baris pertama jauh lebih lebar
+	assert.Equal(t, red, img.At(2, 60))
+	assert.Equal(t, red, img.At(200, 60))
+	assert.Equal(t, red, img.At(2, 150))
+	assert.Equal(t, color.RGBA{}, img.At(200, 150))
+	// di antara dua baris (line spacing 3) tidak ada background
+	assert.Equal(t, color.RGBA{}, img.At(2, 100))
+
+	box.BackgroundMode = TextBackgroundBlock
+	img, err = DrawTextBoxes(image.NewRGBA(image.Rect(0, 0, 400, 200)), text, []TextBox{box}, TextOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, red, img.At(200, 150))
+	assert.Equal(t, red, img.At(2, 100))
+}
+
+func TestDrawTextBoxes_InvalidBackground(t *testing.T) {
+	box := TextBox{Name: "t", Width: 100, Height: 50, Font: testFont, BackgroundColor: "nope"}
+	_, err := DrawTextBoxes(image.NewRGBA(image.Rect(0, 0, 100, 50)), map[string]string{"t": "x"}, []TextBox{box}, TextOptions{})
+	assert.Error(t, err)
+}
+
+func TestDrawTextBoxes_Rotate(t *testing.T) {
+	// box horizontal 160x40 di tengah kanvas, hanya background merah (x 20 sampai sekitar 170) yang terlihat
+	box := TextBox{
+		Name: "t", X: 20, Y: 80, Width: 160, Height: 40,
+		Font: testFont, Size: 20, LineSpacing: 1, Color: "#00000000",
+		BackgroundColor: "#ff0000", BackgroundMode: TextBackgroundBlock, BackgroundPadding: 100,
+	}
+	text := map[string]string{"t": "label"}
+
+	img, err := DrawTextBoxes(image.NewRGBA(image.Rect(0, 0, 200, 200)), text, []TextBox{box}, TextOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, img.At(30, 100))
+	assert.Equal(t, color.RGBA{}, img.At(100, 30))
+
+	// diputar 90 derajat box menjadi vertikal
+	box.Rotate = 90
+	img, err = DrawTextBoxes(image.NewRGBA(image.Rect(0, 0, 200, 200)), text, []TextBox{box}, TextOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{}, img.At(30, 100))
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, img.At(100, 60))
+
+	// skew 45 derajat menggeser bagian atas box ke kanan dan bagian bawah ke kiri
+	box.Rotate, box.SkewX = 0, 45
+	img, err = DrawTextBoxes(image.NewRGBA(image.Rect(0, 0, 200, 200)), text, []TextBox{box}, TextOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{}, img.At(25, 85))
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, img.At(180, 85))
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, img.At(10, 115))
+}
@@ -0,0 +1,115 @@
+package imageutil
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/fixed"
+)
+
+// emojiSet gambar emoji berwarna dari satu folder. Nama file adalah codepoint hex dipisah "-"
+// tanpa FE0F, mis. 1f44d-1f3fd.png (sama dengan penamaan Twemoji), sehingga set bisa diganti.
+type emojiSet struct {
+	dir   string
+	names map[string]bool
+
+	mu     sync.Mutex
+	images map[string]image.Image
+}
+
+// set emoji per folder, isi folder hanya dibaca sekali
+var emojiSets = struct {
+	sync.Mutex
+	sets map[string]*emojiSet
+}{sets: make(map[string]*emojiSet)}
+
+func loadEmojiSet(dir string) (*emojiSet, error) {
+	dir = filepath.Clean(dir)
+
+	emojiSets.Lock()
+	defer emojiSets.Unlock()
+	if s, ok := emojiSets.sets[dir]; ok {
+		return s, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	s := &emojiSet{dir: dir, names: make(map[string]bool, len(entries)), images: make(map[string]image.Image)}
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".png"); ok && !e.IsDir() {
+			s.names[name] = true
+		}
+	}
+	emojiSets.sets[dir] = s
+	return s, nil
+}
+
+// emojiKey nama file untuk satu grapheme cluster
+func emojiKey(cluster []rune) string {
+	parts := make([]string, 0, len(cluster))
+	for _, r := range cluster {
+		if r != 0xfe0f {
+			parts = append(parts, fmt.Sprintf("%x", r))
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// lookup mengembalikan key gambar untuk cluster bila ada di set
+func (s *emojiSet) lookup(cluster []rune) (string, bool) {
+	key := emojiKey(cluster)
+	return key, key != "" && s.names[key]
+}
+
+// emojiPresentation true bila cluster selalu tampil sebagai emoji. Karakter yang defaultnya teks
+// (mis. ©, ❤) hanya digambar sebagai emoji bila diikuti FE0F atau tidak ada di font mana pun.
+func emojiPresentation(cluster []rune) bool {
+	return len(cluster) > 1 || cluster[0] >= 0x1f000
+}
+
+func (s *emojiSet) image(key string) (image.Image, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if img, ok := s.images[key]; ok {
+		return img, nil
+	}
+
+	f, err := os.Open(filepath.Join(s.dir, key+".png"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("emoji %s: %w", key, err)
+	}
+	s.images[key] = img
+	return img, nil
+}
+
+// emojiAdvance lebar emoji yang digambar setinggi ukuran font
+func emojiAdvance(style *textStyle, img image.Image) fixed.Int26_6 {
+	b := img.Bounds()
+	return style.face.size * fixed.Int26_6(b.Dx()) / fixed.Int26_6(b.Dy())
+}
+
+// drawEmoji menggambar emoji setinggi ukuran font dengan titik tengah sejajar tengah huruf
+func drawEmoji(dst draw.Image, style *textStyle, img image.Image, dot fixed.Point26_6) {
+	m := style.face.Metrics()
+	size := float64(style.face.size) / 64
+	width := float64(emojiAdvance(style, img)) / 64
+	x := float64(dot.X) / 64
+	center := float64(dot.Y-(m.Ascent-m.Descent)/2) / 64
+
+	rect := image.Rect(int(math.Round(x)), int(math.Round(center-size/2)), int(math.Round(x+width)), int(math.Round(center+size/2)))
+	draw.CatmullRom.Scale(dst, rect, img, img.Bounds(), draw.Over, nil)
+}
@@ -0,0 +1,79 @@
+package imageutil
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	japaneseFont = "../../../assets/fonts/mplus-1p-regular.ttf"
+	testEmojiDir = "../../../assets/emoji"
+)
+
+func TestStyleSpans_FallbackFont(t *testing.T) {
+	face, err := loadFontFace(testFont, 30)
+	require.NoError(t, err)
+
+	box := TextBox{Font: testFont, FallbackFonts: []string{japaneseFont}}
+	pieces, err := styleSpans(box, []TextSpan{{Text: "Tokyo 日本"}}, TextOptions{}, face, 30, image.Black)
+	require.NoError(t, err)
+	require.Len(t, pieces, 2)
+	assert.Equal(t, "Tokyo ", pieces[0].text)
+	assert.Same(t, face, pieces[0].style.face)
+	assert.Equal(t, "日本", pieces[1].text)
+	assert.True(t, pieces[1].style.face.hasGlyph('日'))
+
+	// tanpa fallback huruf tetap memakai font box
+	pieces, err = styleSpans(TextBox{Font: testFont}, []TextSpan{{Text: "Tokyo 日本"}}, TextOptions{}, face, 30, image.Black)
+	require.NoError(t, err)
+	require.Len(t, pieces, 1)
+}
+
+func TestStyleSpans_Emoji(t *testing.T) {
+	face, err := loadFontFace(hebrewFont, 30)
+	require.NoError(t, err)
+	opts := TextOptions{EmojiDir: testEmojiDir}
+
+	pieces, err := styleSpans(TextBox{Font: hebrewFont}, []TextSpan{{Text: "ok 👍🏽 👨‍👩‍👧"}}, opts, face, 30, image.Black)
+	require.NoError(t, err)
+	var emoji []string
+	for _, p := range pieces {
+		if p.emoji != nil {
+			emoji = append(emoji, p.text)
+		}
+	}
+	assert.Equal(t, []string{"👍🏽", "👨‍👩‍👧"}, emoji)
+
+	// © defaultnya teks, jadi memakai font bila fontnya punya, kecuali diikuti FE0F
+	pieces, err = styleSpans(TextBox{Font: hebrewFont}, []TextSpan{{Text: "©"}}, opts, face, 30, image.Black)
+	require.NoError(t, err)
+	require.Len(t, pieces, 1)
+	assert.Nil(t, pieces[0].emoji)
+
+	pieces, err = styleSpans(TextBox{Font: hebrewFont}, []TextSpan{{Text: "©️"}}, opts, face, 30, image.Black)
+	require.NoError(t, err)
+	require.Len(t, pieces, 1)
+	assert.NotNil(t, pieces[0].emoji)
+}
+
+func TestDrawTextBoxes_Emoji(t *testing.T) {
+	box := TextBox{Name: "t", Width: 200, Height: 80, Font: testFont, Size: 40, Color: "#000000", LineSpacing: 1.2}
+	img, err := DrawTextBoxes(image.NewRGBA(image.Rect(0, 0, 200, 80)), map[string]string{"t": "a🔥b"}, []TextBox{box}, TextOptions{EmojiDir: testEmojiDir})
+	require.NoError(t, err)
+
+	// teks hitam, jadi pixel berwarna hanya berasal dari emoji
+	colored := 0
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			if a > 0x8000 && (r > g+0x4000 || r > b+0x4000) {
+				colored++
+			}
+		}
+	}
+	assert.Greater(t, colored, 100)
+}
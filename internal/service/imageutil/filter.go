@@ -0,0 +1,196 @@
+package imageutil
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+type FilterType string
+
+const (
+	FilterGrayscale  FilterType = "grayscale"
+	FilterBrightness FilterType = "brightness" // amount -100..100
+	FilterContrast   FilterType = "contrast"   // amount -100..100
+	FilterSaturation FilterType = "saturation" // amount -100..500
+	FilterBlur       FilterType = "blur"       // amount = sigma gaussian
+	FilterSharpen    FilterType = "sharpen"    // amount = sigma
+	FilterSepia      FilterType = "sepia"      // amount 0..100, default 100
+	FilterVignette   FilterType = "vignette"   // amount 0..100, default 50
+	FilterTint       FilterType = "tint"       // gradient Color -> To, amount 0..100 = opacity
+)
+
+const maxFilterSigma = 50
+
+type Filter struct {
+	Type   FilterType `json:"type"`
+	Amount *float64   `json:"amount,omitempty"` // nil = default filter, 0 tetap dipakai
+	Color  string     `json:"color,omitempty"`  // warna tint (awal gradient)
+	To     string     `json:"to,omitempty"`     // warna akhir gradient tint, kosong = warna solid
+	Angle  float64    `json:"angle,omitempty"`  // arah gradient tint dalam derajat
+	Blend  string     `json:"blend,omitempty"`  // blend mode tint, default "normal"
+}
+
+// Validate memastikan tipe dan nilai filter masuk akal sebelum dijalankan
+func (f Filter) Validate() error {
+	inRange := func(min, max float64) error {
+		if a := f.amount(0); a < min || a > max {
+			return fmt.Errorf("filter %s: amount must be between %g and %g", f.Type, min, max)
+		}
+		return nil
+	}
+
+	switch f.Type {
+	case FilterGrayscale:
+		return nil
+	case FilterBrightness, FilterContrast:
+		return inRange(-100, 100)
+	case FilterSaturation:
+		return inRange(-100, 500)
+	case FilterBlur, FilterSharpen:
+		return inRange(0, maxFilterSigma)
+	case FilterSepia, FilterVignette:
+		return inRange(0, 100)
+	case FilterTint:
+		if err := inRange(0, 100); err != nil {
+			return err
+		}
+		if _, err := ParseColor(f.Color); err != nil {
+			return fmt.Errorf("filter tint: %w", err)
+		}
+		if f.To != "" {
+			if _, err := ParseColor(f.To); err != nil {
+				return fmt.Errorf("filter tint: %w", err)
+			}
+		}
+		if _, err := ParseBlendMode(f.Blend); err != nil {
+			return fmt.Errorf("filter tint: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid filter type: %s", f.Type)
+	}
+}
+
+// ApplyFilters menjalankan filter secara berurutan
+func ApplyFilters(img image.Image, filters []Filter) (image.Image, error) {
+	for _, f := range filters {
+		if err := f.Validate(); err != nil {
+			return nil, err
+		}
+
+		switch f.Type {
+		case FilterGrayscale:
+			img = imaging.Grayscale(img)
+		case FilterBrightness:
+			img = imaging.AdjustBrightness(img, f.amount(0))
+		case FilterContrast:
+			img = imaging.AdjustContrast(img, f.amount(0))
+		case FilterSaturation:
+			img = imaging.AdjustSaturation(img, f.amount(0))
+		case FilterBlur:
+			img = imaging.Blur(img, f.amount(0))
+		case FilterSharpen:
+			img = imaging.Sharpen(img, f.amount(0))
+		case FilterSepia:
+			img = sepia(img, f.amount(100)/100)
+		case FilterVignette:
+			img = vignette(img, f.amount(50)/100)
+		case FilterTint:
+			tinted, err := tint(img, f)
+			if err != nil {
+				return nil, err
+			}
+			img = tinted
+		}
+	}
+
+	return img, nil
+}
+
+// amount nilai amount filter, def bila tidak diisi
+func (f Filter) amount(def float64) float64 {
+	if f.Amount == nil {
+		return def
+	}
+	return *f.Amount
+}
+
+func sepia(img image.Image, strength float64) image.Image {
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		r, g, b := float64(c.R), float64(c.G), float64(c.B)
+		sr := 0.393*r + 0.769*g + 0.189*b
+		sg := 0.349*r + 0.686*g + 0.168*b
+		sb := 0.272*r + 0.534*g + 0.131*b
+		return color.NRGBA{
+			R: toByte((r + (sr-r)*strength) / 255),
+			G: toByte((g + (sg-g)*strength) / 255),
+			B: toByte((b + (sb-b)*strength) / 255),
+			A: c.A,
+		}
+	})
+}
+
+// vignette menggelapkan tepi gambar secara radial, strength 0..1
+func vignette(img image.Image, strength float64) image.Image {
+	dst := imaging.Clone(img)
+	w, h := dst.Rect.Dx(), dst.Rect.Dy()
+	cx, cy := float64(w)/2, float64(h)/2
+	maxDist := math.Hypot(cx, cy)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			d := math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy) / maxDist
+			// mulai menggelap dari 40% jarak ke sudut
+			t := (d - 0.4) / 0.6
+			if t <= 0 {
+				continue
+			}
+			k := 1 - strength*t*t
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(float64(dst.Pix[i]) * k)
+			dst.Pix[i+1] = uint8(float64(dst.Pix[i+1]) * k)
+			dst.Pix[i+2] = uint8(float64(dst.Pix[i+2]) * k)
+		}
+	}
+
+	return dst
+}
+
+// tint mewarnai gambar dengan warna solid atau gradient, hanya pada area yang tidak transparan
+func tint(img image.Image, f Filter) (image.Image, error) {
+	b := img.Bounds()
+	shape := Shape{
+		Kind:   ShapeRect,
+		Width:  float64(b.Dx()),
+		Height: float64(b.Dy()),
+		Color:  f.Color,
+	}
+	if f.To != "" {
+		shape.Kind = ShapeGradient
+		shape.From = f.Color
+		shape.To = f.To
+		shape.Angle = f.Angle
+	}
+
+	layer, err := DrawShape(b.Dx(), b.Dy(), shape)
+	if err != nil {
+		return nil, err
+	}
+
+	mode, _ := ParseBlendMode(f.Blend)
+	dst := NewCanvas(b.Dx(), b.Dy())
+	DrawLayer(dst, img, 0, 0, 1)
+	Composite(dst, layer, 0, 0, mode, f.amount(50)/100)
+
+	// kembalikan alpha asli supaya tint tidak mengisi area transparan
+	src := imaging.Clone(img)
+	out := imaging.Clone(dst)
+	for i := 3; i < len(out.Pix); i += 4 {
+		out.Pix[i] = src.Pix[i]
+	}
+	return out, nil
+}
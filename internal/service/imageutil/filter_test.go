@@ -0,0 +1,99 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func filled(w, h int, c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func amount(v float64) *float64 { return &v }
+
+func nrgbaAt(img image.Image, x, y int) color.NRGBA {
+	return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+}
+
+func TestApplyFilters_Sepia(t *testing.T) {
+	src := filled(1, 1, color.NRGBA{R: 100, G: 50, B: 20, A: 255})
+
+	// tanpa amount = kekuatan penuh
+	img, err := ApplyFilters(src, []Filter{{Type: FilterSepia}})
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 82, G: 73, B: 57, A: 255}, nrgbaAt(img, 0, 0))
+
+	img, err = ApplyFilters(src, []Filter{{Type: FilterSepia, Amount: amount(0)}})
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 100, G: 50, B: 20, A: 255}, nrgbaAt(img, 0, 0), "amount 0 tidak mengubah gambar")
+}
+
+func TestApplyFilters_Vignette(t *testing.T) {
+	src := filled(10, 10, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+
+	img, err := ApplyFilters(src, []Filter{{Type: FilterVignette}})
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, nrgbaAt(img, 5, 5), "tengah tidak berubah")
+	// sudut: jarak 0.9 dari tengah, 1 - 0.5 * ((0.9-0.4)/0.6)^2
+	assert.Equal(t, color.NRGBA{R: 166, G: 166, B: 166, A: 255}, nrgbaAt(img, 0, 0))
+
+	img, err = ApplyFilters(src, []Filter{{Type: FilterVignette, Amount: amount(0)}})
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, nrgbaAt(img, 0, 0))
+}
+
+func TestApplyFilters_Tint(t *testing.T) {
+	src := filled(2, 1, color.NRGBA{R: 100, G: 100, B: 100, A: 255})
+	src.SetNRGBA(1, 0, color.NRGBA{})
+
+	img, err := ApplyFilters(src, []Filter{{Type: FilterTint, Color: "#ff0000"}})
+	require.NoError(t, err)
+	got := nrgbaAt(img, 0, 0)
+	assert.InDelta(t, 178, got.R, 1, "default opacity 50")
+	assert.InDelta(t, 50, got.G, 1)
+	assert.Equal(t, uint8(255), got.A)
+	assert.Equal(t, uint8(0), nrgbaAt(img, 1, 0).A, "area transparan tidak diwarnai")
+
+	img, err = ApplyFilters(src, []Filter{{Type: FilterTint, Color: "#ff0000", Amount: amount(100), Blend: "multiply"}})
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 100, A: 255}, nrgbaAt(img, 0, 0))
+
+	img, err = ApplyFilters(src, []Filter{{Type: FilterTint, Color: "#ff0000", Amount: amount(0)}})
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 100, G: 100, B: 100, A: 255}, nrgbaAt(img, 0, 0))
+}
+
+func TestApplyFilters_Pipeline(t *testing.T) {
+	src := filled(1, 1, color.NRGBA{R: 200, G: 100, B: 0, A: 255})
+
+	// filter dijalankan berurutan: grayscale lalu brightness -100 menjadi hitam
+	img, err := ApplyFilters(src, []Filter{{Type: FilterGrayscale}, {Type: FilterBrightness, Amount: amount(-100)}})
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{A: 255}, nrgbaAt(img, 0, 0))
+
+	img, err = ApplyFilters(src, []Filter{{Type: FilterGrayscale}})
+	require.NoError(t, err)
+	c := nrgbaAt(img, 0, 0)
+	assert.Equal(t, c.R, c.G)
+	assert.Equal(t, c.G, c.B)
+
+	for _, f := range []Filter{
+		{Type: "posterize"},
+		{Type: FilterBrightness, Amount: amount(150)},
+		{Type: FilterBlur, Amount: amount(-1)},
+		{Type: FilterSepia, Amount: amount(101)},
+		{Type: FilterTint},
+		{Type: FilterTint, Color: "#fff", Blend: "burn"},
+	} {
+		_, err := ApplyFilters(src, []Filter{f})
+		assert.Error(t, err, "%+v", f)
+	}
+}
@@ -0,0 +1,112 @@
+package imageutil
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	otfont "github.com/go-text/typesetting/font"
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// parsedFont satu file font: truetype untuk menggambar teks biasa per rune,
+// otfont untuk shaping aksara kompleks dan kanan ke kiri
+type parsedFont struct {
+	tt *truetype.Font
+	ot *otfont.Font
+}
+
+// font yang sudah di-parse disimpan supaya file TTF tidak dibaca ulang setiap request
+var fontCache = struct {
+	sync.RWMutex
+	fonts map[string]*parsedFont
+}{fonts: make(map[string]*parsedFont)}
+
+func loadFont(path string) (*parsedFont, error) {
+	path = filepath.Clean(path)
+
+	fontCache.RLock()
+	f, ok := fontCache.fonts[path]
+	fontCache.RUnlock()
+	if ok {
+		return f, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tt, err := truetype.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	ot, err := otfont.ParseTTF(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	f = &parsedFont{tt: tt, ot: ot.Font}
+
+	fontCache.Lock()
+	fontCache.fonts[path] = f
+	fontCache.Unlock()
+	return f, nil
+}
+
+// fontFace face truetype untuk satu ukuran beserta face go-text untuk shaping
+type fontFace struct {
+	font.Face
+	shape *otfont.Face
+	size  fixed.Int26_6 // ukuran dalam pixel
+}
+
+// loadFontFace membuat face baru dari font yang di-cache. Face tidak aman dipakai
+// bersamaan oleh beberapa goroutine, jadi dibuat per pemanggilan DrawTextBoxes.
+func loadFontFace(path string, size float64) (*fontFace, error) {
+	f, err := loadFont(path)
+	if err != nil {
+		return nil, err
+	}
+	return &fontFace{
+		Face:  truetype.NewFace(f.tt, &truetype.Options{Size: size}),
+		shape: otfont.NewFace(f.ot),
+		size:  fixed.Int26_6(size * 64),
+	}, nil
+}
+
+func (f *fontFace) hasGlyph(r rune) bool {
+	_, ok := f.shape.NominalGlyph(r)
+	return ok
+}
+
+// fontChain memilih face untuk setiap huruf: font box dulu, lalu fallback sesuai urutan.
+// Font fallback baru dimuat saat ada huruf yang tidak ditemukan di font sebelumnya.
+type fontChain struct {
+	paths []string
+	size  float64
+	faces []*fontFace // nil = belum dimuat
+}
+
+func newFontChain(paths []string, size float64) *fontChain {
+	return &fontChain{paths: paths, size: size, faces: make([]*fontFace, len(paths))}
+}
+
+// faceFor mengembalikan index font pertama di chain yang punya huruf r, atau -1 bila tidak ada
+func (c *fontChain) faceFor(r rune) (int, error) {
+	for i := range c.paths {
+		if c.faces[i] == nil {
+			face, err := loadFontFace(c.paths[i], c.size)
+			if err != nil {
+				return -1, fmt.Errorf("failed to load font %s: %w", c.paths[i], err)
+			}
+			c.faces[i] = face
+		}
+		if c.faces[i].hasGlyph(r) {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
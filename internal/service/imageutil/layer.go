@@ -0,0 +1,39 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/disintegration/imaging"
+)
+
+// NewCanvas membuat kanvas RGBA transparan berukuran w x h
+func NewCanvas(w, h int) *image.RGBA {
+	return image.NewRGBA(image.Rect(0, 0, w, h))
+}
+
+// Rotate memutar gambar (derajat, berlawanan jarum jam), area kosong dibuat transparan
+func Rotate(img image.Image, angle float64) image.Image {
+	if angle == 0 {
+		return img
+	}
+	return imaging.Rotate(img, angle, image.Transparent)
+}
+
+// DrawLayer menggambar src di atas dst pada posisi (x, y) dengan opacity 0..1
+func DrawLayer(dst draw.Image, src image.Image, x, y int, opacity float64) {
+	if opacity <= 0 {
+		return
+	}
+
+	sb := src.Bounds()
+	r := image.Rect(x, y, x+sb.Dx(), y+sb.Dy())
+	if opacity >= 1 {
+		draw.Draw(dst, r, src, sb.Min, draw.Over)
+		return
+	}
+
+	mask := image.NewUniform(color.Alpha{A: uint8(opacity*255 + 0.5)})
+	draw.DrawMask(dst, r, src, sb.Min, mask, image.Point{}, draw.Over)
+}
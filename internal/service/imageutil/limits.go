@@ -0,0 +1,58 @@
+package imageutil
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+)
+
+var (
+	ErrImageTooLarge = errors.New("image dimensions too large")
+	ErrInvalidImage  = errors.New("invalid image")
+)
+
+// DecodeLimits batas ukuran gambar sebelum decode penuh, 0 = tidak dibatasi
+type DecodeLimits struct {
+	MaxWidth  int
+	MaxHeight int
+	MaxPixels int
+}
+
+// DefaultDecodeLimits dipakai bila pemanggil tidak memberikan batas sendiri
+var DefaultDecodeLimits = DecodeLimits{
+	MaxWidth:  8000,
+	MaxHeight: 8000,
+	MaxPixels: 40_000_000,
+}
+
+func (l DecodeLimits) isZero() bool {
+	return l == DecodeLimits{}
+}
+
+// CheckLimits membaca header gambar saja (image.DecodeConfig) dan menolak dimensi yang
+// melebihi batas, supaya PNG kecil yang mengklaim 30000x30000 tidak sempat dialokasikan
+func CheckLimits(data []byte, limits DecodeLimits) (image.Config, string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return cfg, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if limits.isZero() {
+		limits = DefaultDecodeLimits
+	}
+
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return cfg, format, fmt.Errorf("%w: empty image", ErrInvalidImage)
+	}
+	if limits.MaxWidth > 0 && cfg.Width > limits.MaxWidth {
+		return cfg, format, fmt.Errorf("%w: width %d exceeds %d", ErrImageTooLarge, cfg.Width, limits.MaxWidth)
+	}
+	if limits.MaxHeight > 0 && cfg.Height > limits.MaxHeight {
+		return cfg, format, fmt.Errorf("%w: height %d exceeds %d", ErrImageTooLarge, cfg.Height, limits.MaxHeight)
+	}
+	if limits.MaxPixels > 0 && int64(cfg.Width)*int64(cfg.Height) > int64(limits.MaxPixels) {
+		return cfg, format, fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrImageTooLarge, cfg.Width, cfg.Height, limits.MaxPixels)
+	}
+
+	return cfg, format, nil
+}
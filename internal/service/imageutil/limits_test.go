@@ -0,0 +1,134 @@
+package imageutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// craftedPNG hanya berisi header PNG (IHDR) yang mengklaim dimensi w x h
+func craftedPNG(w, h uint32) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], w)
+	binary.BigEndian.PutUint32(ihdr[4:], h)
+	ihdr[8] = 8 // bit depth
+	ihdr[9] = 2 // truecolor
+
+	writeChunk := func(typ string, data []byte) {
+		_ = binary.Write(&buf, binary.BigEndian, uint32(len(data)))
+		chunk := append([]byte(typ), data...)
+		buf.Write(chunk)
+		_ = binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	}
+	writeChunk("IHDR", ihdr)
+	writeChunk("IDAT", []byte{0x78, 0x9c, 0x03, 0x00, 0x00, 0x00, 0x00, 0x01})
+	writeChunk("IEND", nil)
+	return buf.Bytes()
+}
+
+// craftedGIF header GIF dengan logical screen w x h tanpa frame
+func craftedGIF(w, h uint16) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("GIF89a")
+	_ = binary.Write(&buf, binary.LittleEndian, w)
+	_ = binary.Write(&buf, binary.LittleEndian, h)
+	buf.Write([]byte{0x00, 0x00, 0x00, 0x3b})
+	return buf.Bytes()
+}
+
+// craftedJPEG SOI + SOF0 yang mengklaim dimensi w x h, diikuti SOS tanpa data scan
+func craftedJPEG(w, h uint16) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{0xFF, 0xD8, 0xFF, 0xC0, 0x00, 0x11, 0x08})
+	_ = binary.Write(&buf, binary.BigEndian, h)
+	_ = binary.Write(&buf, binary.BigEndian, w)
+	buf.Write([]byte{0x03, 0x01, 0x22, 0x00, 0x02, 0x11, 0x01, 0x03, 0x11, 0x01})
+	buf.Write([]byte{0xFF, 0xDA, 0x00, 0x0C, 0x03, 0x01, 0x00, 0x02, 0x11, 0x03, 0x11, 0x00, 0x3F, 0x00, 0xFF, 0xD9})
+	return buf.Bytes()
+}
+
+func smallPNG(t testing.TB) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 16, 16))))
+	return buf.Bytes()
+}
+
+func TestCheckLimits_RejectsCraftedHeaders(t *testing.T) {
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{"PNG 30000x30000", craftedPNG(30000, 30000)},
+		{"PNG wide", craftedPNG(100000, 1)},
+		{"GIF 65535x65535", craftedGIF(65535, 65535)},
+		{"JPEG 30000x30000", craftedJPEG(30000, 30000)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := CheckLimits(tc.data, DecodeLimits{})
+			assert.ErrorIs(t, err, ErrImageTooLarge)
+
+			_, err = Decode(tc.data, DecodeLimits{})
+			assert.ErrorIs(t, err, ErrImageTooLarge)
+		})
+	}
+}
+
+func TestCheckLimits_PixelBudget(t *testing.T) {
+	limits := DecodeLimits{MaxWidth: 5000, MaxHeight: 5000, MaxPixels: 1_000_000}
+
+	_, _, err := CheckLimits(craftedPNG(2000, 1000), limits)
+	assert.ErrorIs(t, err, ErrImageTooLarge)
+
+	cfg, format, err := CheckLimits(smallPNG(t), limits)
+	assert.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, 16, cfg.Width)
+}
+
+func TestCheckLimits_InvalidImage(t *testing.T) {
+	_, _, err := CheckLimits([]byte("not an image"), DecodeLimits{})
+	assert.ErrorIs(t, err, ErrInvalidImage)
+
+	_, _, err = CheckLimits(craftedPNG(0, 0), DecodeLimits{})
+	assert.Error(t, err)
+}
+
+func FuzzDecode(f *testing.F) {
+	f.Add(smallPNG(f))
+	f.Add(craftedPNG(30000, 30000))
+	f.Add(craftedPNG(64, 64))
+	f.Add(craftedGIF(65535, 65535))
+	f.Add(craftedGIF(10, 10))
+	f.Add(craftedJPEG(30000, 30000))
+	f.Add(craftedJPEG(8, 8))
+
+	limits := DecodeLimits{MaxWidth: 512, MaxHeight: 512, MaxPixels: 128 * 128}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_ = CheckAnimationLimits(data, AnimationLimits{MaxFrames: 10})
+
+		img, err := Decode(data, limits)
+		if err != nil {
+			return
+		}
+
+		b := img.Bounds()
+		// auto orientation bisa menukar width dan height
+		if b.Dx() > limits.MaxWidth && b.Dx() > limits.MaxHeight {
+			t.Fatalf("decoded width %d exceeds limits", b.Dx())
+		}
+		if b.Dx()*b.Dy() > limits.MaxPixels {
+			t.Fatalf("decoded %dx%d exceeds pixel limit", b.Dx(), b.Dy())
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package imageutil
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"unicode/utf8"
+)
+
+// TextSpan potongan teks dengan gaya sendiri di dalam satu text box
+type TextSpan struct {
+	Text  string
+	Bold  bool
+	Color color.Color // nil = warna text box
+}
+
+// ParseMarkup memecah teks user menjadi span. Markup yang didukung: **tebal**, [b]tebal[/b]
+// dan [color=#ff0000]warna[/color]. Tag yang tidak dikenal atau tidak ditutup dibiarkan
+// sebagai teks biasa, \ meng-escape karakter berikutnya.
+//
+// Tag pembuka hanya berlaku bila penutupnya ada setelahnya. Posisi penutup terakhir dicari
+// sekali di awal supaya parsing tetap linear untuk teks panjang.
+func ParseMarkup(s string) ([]TextSpan, error) {
+	var (
+		spans    []TextSpan
+		buf      strings.Builder
+		bufSpan  TextSpan // gaya teks yang sedang dikumpulkan di buf
+		starBold bool
+		tagBold  int
+		colors   []color.Color
+	)
+
+	lastStar := strings.LastIndex(s, "**")
+	lastBold := strings.LastIndex(s, "[/b]")
+	lastColor := strings.LastIndex(s, "[/color]")
+	bracket := -1 // posisi ']' berikutnya, dicari ulang hanya setelah terlewati
+
+	current := func() TextSpan {
+		span := TextSpan{Bold: starBold || tagBold > 0}
+		if len(colors) > 0 {
+			span.Color = colors[len(colors)-1]
+		}
+		return span
+	}
+	flush := func() {
+		if buf.Len() == 0 {
+			return
+		}
+		bufSpan.Text = buf.String()
+		spans = append(spans, bufSpan)
+		buf.Reset()
+	}
+	// write menambah teks ke span berjalan, span baru dimulai hanya bila gayanya berubah
+	write := func(text string) {
+		span := current()
+		if buf.Len() > 0 && (span.Bold != bufSpan.Bold || span.Color != bufSpan.Color) {
+			flush()
+		}
+		if buf.Len() == 0 {
+			bufSpan = span
+		}
+		buf.WriteString(text)
+	}
+
+	for i := 0; i < len(s); {
+		rest := s[i:]
+
+		switch {
+		case rest[0] == '\\' && len(rest) > 1:
+			_, size := utf8.DecodeRuneInString(rest[1:])
+			write(rest[1 : 1+size])
+			i += 1 + size
+			continue
+		case strings.HasPrefix(rest, "**"):
+			if starBold || lastStar >= i+2 {
+				starBold = !starBold
+				i += 2
+				continue
+			}
+		case strings.HasPrefix(rest, "[b]"):
+			if lastBold >= i {
+				tagBold++
+				i += len("[b]")
+				continue
+			}
+		case strings.HasPrefix(rest, "[/b]"):
+			if tagBold > 0 {
+				tagBold--
+				i += len("[/b]")
+				continue
+			}
+		case strings.HasPrefix(rest, "[color="):
+			if bracket < i {
+				bracket = len(s)
+				if j := strings.IndexByte(rest, ']'); j >= 0 {
+					bracket = i + j
+				}
+			}
+			if bracket < len(s) && lastColor >= bracket {
+				value := s[i+len("[color=") : bracket]
+				c, err := ParseColor(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid color %q in markup", value)
+				}
+				colors = append(colors, c)
+				i = bracket + 1
+				continue
+			}
+		case strings.HasPrefix(rest, "[/color]"):
+			if len(colors) > 0 {
+				colors = colors[:len(colors)-1]
+				i += len("[/color]")
+				continue
+			}
+		}
+
+		_, size := utf8.DecodeRuneInString(rest)
+		write(rest[:size])
+		i += size
+	}
+	flush()
+
+	return spans, nil
+}
+
+// truncateSpans memotong span supaya jumlah karakter yang terlihat tidak melebihi max
+func truncateSpans(spans []TextSpan, max int) []TextSpan {
+	count := 0
+	for i, span := range spans {
+		n := utf8.RuneCountInString(span.Text)
+		if count+n <= max {
+			count += n
+			continue
+		}
+
+		runes := []rune(span.Text)
+		spans[i].Text = string(runes[:max-count])
+		if spans[i].Text == "" {
+			return spans[:i]
+		}
+		return spans[:i+1]
+	}
+	return spans
+}
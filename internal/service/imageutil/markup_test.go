@@ -0,0 +1,67 @@
+package imageutil
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMarkup(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+
+	cases := []struct {
+		name string
+		in   string
+		want []TextSpan
+	}{
+		{"plain", "hello world", []TextSpan{{Text: "hello world"}}},
+		{"star bold", "a **b** c", []TextSpan{{Text: "a "}, {Text: "b", Bold: true}, {Text: " c"}}},
+		{"tag bold", "[b]a[/b]b", []TextSpan{{Text: "a", Bold: true}, {Text: "b"}}},
+		{"color", "[color=#ff0000]hot[/color] take", []TextSpan{{Text: "hot", Color: red}, {Text: " take"}}},
+		{"nested", "[color=#ff0000]a **b**[/color]", []TextSpan{{Text: "a ", Color: red}, {Text: "b", Bold: true, Color: red}}},
+		{"unclosed star", "5 ** 2", []TextSpan{{Text: "5 ** 2"}}},
+		{"unclosed tag", "[b]open [color=#ff0000]x", []TextSpan{{Text: "[b]open [color=#ff0000]x"}}},
+		{"stray closing", "a[/b][/color]", []TextSpan{{Text: "a[/b][/color]"}}},
+		{"unknown tag", "[i]x[/i]", []TextSpan{{Text: "[i]x[/i]"}}},
+		{"escape", `\*\*not bold\*\* \[b]`, []TextSpan{{Text: "**not bold** [b]"}}},
+		{"empty tags merge", "a[b][/b]b[color=#ff0000][/color]c", []TextSpan{{Text: "abc"}}},
+		{"color without closing bracket", "[color=#ff0000 x[/color]", []TextSpan{{Text: "[color=#ff0000 x[/color]"}}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			spans, err := ParseMarkup(tc.in)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, spans)
+		})
+	}
+}
+
+func TestParseMarkup_LongInput(t *testing.T) {
+	// tag pembuka tanpa penutup dulu membuat setiap tag mencari penutup sampai akhir teks
+	for _, unit := range []string{"[color=red]", "[b]", "**x", "[color=", "a[b][/b]"} {
+		in := strings.Repeat(unit, 1<<20/len(unit))
+		start := time.Now()
+		spans, err := ParseMarkup(in)
+		require.NoError(t, err)
+		assert.NotEmpty(t, spans)
+		assert.Less(t, time.Since(start), 2*time.Second, unit)
+	}
+}
+
+func TestParseMarkup_InvalidColor(t *testing.T) {
+	_, err := ParseMarkup("[color=red-ish]x[/color]")
+	assert.Error(t, err)
+}
+
+func TestTruncateSpans_CountsVisibleRunes(t *testing.T) {
+	spans, err := ParseMarkup("ab**cdé**fg")
+	require.NoError(t, err)
+
+	assert.Equal(t, []TextSpan{{Text: "ab"}, {Text: "cdé", Bold: true}}, truncateSpans(spans, 5))
+	assert.Equal(t, []TextSpan{{Text: "ab"}}, truncateSpans(spans, 2))
+}
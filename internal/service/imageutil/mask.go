@@ -0,0 +1,107 @@
+package imageutil
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/fogleman/gg"
+)
+
+type MaskKind string
+
+const (
+	MaskRoundedRect MaskKind = "rounded_rect"
+	MaskCircle      MaskKind = "circle"  // lingkaran terbesar di tengah slot
+	MaskEllipse     MaskKind = "ellipse" // elips seukuran slot
+	MaskImage       MaskKind = "image"   // alpha gambar, atau kecerahannya bila gambar tidak transparan
+)
+
+// Mask bentuk slot overlay, bagian di luar mask menjadi transparan
+type Mask struct {
+	Kind   MaskKind `json:"kind"`
+	Radius float64  `json:"radius,omitempty"` // sudut rounded_rect dalam pixel
+	Image  string   `json:"image,omitempty"`  // path gambar mask untuk kind image, ditarik seukuran slot
+}
+
+func (m Mask) Validate() error {
+	switch m.Kind {
+	case MaskRoundedRect:
+		if m.Radius <= 0 {
+			return errors.New("rounded_rect mask needs a radius")
+		}
+	case MaskCircle, MaskEllipse:
+	case MaskImage:
+		if m.Image == "" {
+			return errors.New("image mask needs an image")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid mask kind: %s", m.Kind)
+	}
+	if m.Image != "" {
+		return fmt.Errorf("%s mask doesn't use an image", m.Kind)
+	}
+	return nil
+}
+
+// Render membuat alpha mask seukuran w x h. src gambar mask yang sudah di-decode untuk kind
+// image, diabaikan untuk kind lain.
+func (m Mask) Render(w, h int, src image.Image) (*image.Alpha, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	if m.Kind == MaskImage {
+		if src == nil {
+			return nil, errors.New("image mask is not loaded")
+		}
+		return imageMask(ResizeWithoutLockRatio(src, w, h)), nil
+	}
+
+	dc := gg.NewContext(w, h)
+	fw, fh := float64(w), float64(h)
+	switch m.Kind {
+	case MaskRoundedRect:
+		dc.DrawRoundedRectangle(0, 0, fw, fh, math.Min(m.Radius, math.Min(fw, fh)/2))
+	case MaskCircle:
+		dc.DrawCircle(fw/2, fh/2, math.Min(fw, fh)/2)
+	case MaskEllipse:
+		dc.DrawEllipse(fw/2, fh/2, fw/2, fh/2)
+	}
+	dc.SetColor(color.White)
+	dc.Fill()
+
+	alpha := image.NewAlpha(image.Rect(0, 0, w, h))
+	draw.Draw(alpha, alpha.Rect, dc.Image(), image.Point{}, draw.Src)
+	return alpha, nil
+}
+
+// imageMask memakai alpha gambar. Gambar yang seluruhnya opaque (mask hitam putih) dipakai
+// kecerahannya: putih terlihat, hitam transparan.
+func imageMask(img image.Image) *image.Alpha {
+	b := img.Bounds()
+	alpha := image.NewAlpha(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(alpha, alpha.Rect, img, b.Min, draw.Src)
+	for _, a := range alpha.Pix {
+		if a != 0xff {
+			return alpha
+		}
+	}
+
+	gray := image.NewGray(alpha.Rect)
+	draw.Draw(gray, gray.Rect, img, b.Min, draw.Src)
+	copy(alpha.Pix, gray.Pix)
+	return alpha
+}
+
+// ApplyMask mengembalikan salinan img yang dipotong mask, mask dimulai di pojok kiri atas img.
+// Bagian img di luar mask menjadi transparan.
+func ApplyMask(img image.Image, mask *image.Alpha) *image.RGBA {
+	b := img.Bounds()
+	out := NewCanvas(b.Dx(), b.Dy())
+	draw.DrawMask(out, out.Rect, img, b.Min, mask, image.Point{}, draw.Src)
+	return out
+}
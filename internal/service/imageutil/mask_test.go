@@ -0,0 +1,62 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMask_Circle(t *testing.T) {
+	mask, err := Mask{Kind: MaskCircle}.Render(200, 100, nil)
+	require.NoError(t, err)
+
+	src := NewCanvas(200, 100)
+	draw.Draw(src, src.Rect, image.NewUniform(color.RGBA{R: 0xff, A: 0xff}), image.Point{}, draw.Src)
+	img := ApplyMask(src, mask)
+	assert.Equal(t, color.RGBA{R: 0xff, A: 0xff}, img.At(100, 50))
+	assert.Equal(t, color.RGBA{R: 0xff, A: 0xff}, img.At(55, 50), "tepi kiri lingkaran di x=50")
+	assert.Equal(t, color.RGBA{}, img.At(45, 50))
+	assert.Equal(t, color.RGBA{}, img.At(0, 0))
+}
+
+func TestMask_RoundedRect(t *testing.T) {
+	mask, err := Mask{Kind: MaskRoundedRect, Radius: 20}.Render(100, 100, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint8(0), mask.AlphaAt(1, 1).A)
+	assert.Equal(t, uint8(0xff), mask.AlphaAt(50, 1).A)
+	assert.Equal(t, uint8(0xff), mask.AlphaAt(50, 50).A)
+}
+
+func TestMask_Image(t *testing.T) {
+	// mask opaque dipakai kecerahannya: kiri hitam, kanan putih
+	src := image.NewGray(image.Rect(0, 0, 2, 1))
+	src.Pix[1] = 0xff
+	mask, err := Mask{Kind: MaskImage, Image: "mask.png"}.Render(100, 10, src)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(0), mask.AlphaAt(5, 5).A)
+	assert.Equal(t, uint8(0xff), mask.AlphaAt(95, 5).A)
+
+	// mask transparan dipakai alpha-nya
+	alpha := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	alpha.Pix[3] = 0x80
+	mask, err = Mask{Kind: MaskImage, Image: "mask.png"}.Render(10, 10, alpha)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(0x80), mask.AlphaAt(5, 5).A)
+}
+
+func TestMask_Validate(t *testing.T) {
+	for _, m := range []Mask{
+		{},
+		{Kind: "star"},
+		{Kind: MaskRoundedRect},
+		{Kind: MaskImage},
+		{Kind: MaskCircle, Image: "mask.png"},
+	} {
+		assert.Error(t, m.Validate(), "%+v", m)
+	}
+}
@@ -0,0 +1,240 @@
+package imageutil
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fogleman/gg"
+)
+
+const (
+	paintSolid   = "solid"
+	paintLinear  = "linear-gradient"
+	paintRadial  = "radial-gradient"
+	paintPattern = "url"
+)
+
+// Paint isi teks: warna solid, gradient, atau gambar pattern yang diulang. Ditulis sebagai string:
+//
+//	#ff0000
+//	linear-gradient(90deg, #ff0000, #0000ff 80%)  sudut 0 = kiri ke kanan, 90 = atas ke bawah
+//	radial-gradient(#ffffff, #000000)             dari tengah area ke sudutnya
+//	url(assets/textures/gold.png)
+type Paint struct {
+	kind  string
+	color color.Color
+	angle float64
+	stops []colorStop
+	image image.Image
+}
+
+type colorStop struct {
+	pos   float64 // 0..1
+	color color.Color
+}
+
+// ParsePaint membaca warna atau spesifikasi fill. Gambar pattern dibaca sekali lalu di-cache.
+func ParsePaint(s string) (*Paint, error) {
+	s = strings.TrimSpace(s)
+	name, args, ok := parseFunc(s)
+	if !ok {
+		c, err := ParseColor(s)
+		if err != nil {
+			return nil, err
+		}
+		return &Paint{kind: paintSolid, color: c}, nil
+	}
+
+	switch name {
+	case paintLinear, paintRadial:
+		return parseGradient(name, args)
+	case paintPattern:
+		img, err := loadPattern(strings.Trim(args, `"' `))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+		return &Paint{kind: paintPattern, image: img}, nil
+	}
+	return nil, fmt.Errorf("invalid paint: %s", s)
+}
+
+// parseFunc memecah "name(args)"
+func parseFunc(s string) (string, string, bool) {
+	open := strings.IndexByte(s, '(')
+	if open <= 0 || !strings.HasSuffix(s, ")") {
+		return "", "", false
+	}
+	return strings.ToLower(strings.TrimSpace(s[:open])), s[open+1 : len(s)-1], true
+}
+
+// splitArgs memisah argumen dengan koma yang tidak berada di dalam kurung
+func splitArgs(s string) []string {
+	var args []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(args, strings.TrimSpace(s[start:]))
+}
+
+func parseGradient(kind, args string) (*Paint, error) {
+	f := &Paint{kind: kind}
+	parts := splitArgs(args)
+	if kind == paintLinear && len(parts) > 0 {
+		if deg, ok := strings.CutSuffix(parts[0], "deg"); ok {
+			angle, err := strconv.ParseFloat(strings.TrimSpace(deg), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid gradient angle: %s", parts[0])
+			}
+			f.angle = angle
+			parts = parts[1:]
+		}
+	}
+	if len(parts) < 2 {
+		return nil, errors.New("gradient needs at least two colors")
+	}
+
+	for i, part := range parts {
+		// posisi opsional di akhir stop, tanpa posisi stop dibagi rata
+		stop := colorStop{pos: float64(i) / float64(len(parts)-1)}
+		if at := strings.LastIndexByte(part, ' '); at > 0 && strings.HasSuffix(part, "%") {
+			pos, err := strconv.ParseFloat(strings.TrimSuffix(part[at+1:], "%"), 64)
+			if err != nil || pos < 0 || pos > 100 {
+				return nil, fmt.Errorf("invalid color stop position: %s", part)
+			}
+			stop.pos, part = pos/100, strings.TrimSpace(part[:at])
+		}
+		c, err := ParseColor(part)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 && stop.pos < f.stops[i-1].pos {
+			return nil, fmt.Errorf("color stops out of order: %s", parts[i])
+		}
+		stop.color = c
+		f.stops = append(f.stops, stop)
+	}
+	return f, nil
+}
+
+// source gambar isi dalam koordinat kanvas. Gradient direntang pada area, pattern diulang mulai
+// dari pojok kiri atas area.
+func (f *Paint) source(x, y, w, h float64) image.Image {
+	var p gg.Pattern
+	var offset, tile image.Point
+	switch f.kind {
+	case paintLinear:
+		// garis gradient melewati pusat area dan cukup panjang untuk menutupi seluruh area
+		rad := f.angle * math.Pi / 180
+		dx, dy := math.Cos(rad), math.Sin(rad)
+		half := math.Abs(w/2*dx) + math.Abs(h/2*dy)
+		cx, cy := x+w/2, y+h/2
+		grad := gg.NewLinearGradient(cx-dx*half, cy-dy*half, cx+dx*half, cy+dy*half)
+		f.addStops(grad)
+		p = grad
+	case paintRadial:
+		cx, cy := x+w/2, y+h/2
+		grad := gg.NewRadialGradient(cx, cy, 0, cx, cy, math.Hypot(w, h)/2)
+		f.addStops(grad)
+		p = grad
+	case paintPattern:
+		p = gg.NewSurfacePattern(f.image, gg.RepeatBoth)
+		offset = image.Pt(int(math.Floor(x)), int(math.Floor(y)))
+		tile = f.image.Bounds().Size()
+	default:
+		return image.NewUniform(f.color)
+	}
+	return patternImage{pattern: p, offset: offset, tile: tile}
+}
+
+// pattern isi sebagai gg.Pattern untuk fill dan stroke path, direntang pada area seperti source
+func (f *Paint) pattern(x, y, w, h float64) gg.Pattern {
+	if src, ok := f.source(x, y, w, h).(patternImage); ok {
+		return src
+	}
+	return gg.NewSolidPattern(f.color)
+}
+
+func (f *Paint) addStops(grad gg.Gradient) {
+	for _, s := range f.stops {
+		grad.AddColorStop(s.pos, s.color)
+	}
+}
+
+// patternImage gg.Pattern sebagai image.Image tanpa batas
+type patternImage struct {
+	pattern gg.Pattern
+	offset  image.Point
+	tile    image.Point // ukuran gambar pattern, nol untuk gradient
+}
+
+func (p patternImage) ColorModel() color.Model { return color.RGBAModel }
+
+func (p patternImage) Bounds() image.Rectangle {
+	return image.Rect(-1e9, -1e9, 1e9, 1e9)
+}
+
+// ColorAt supaya patternImage juga bisa dipakai langsung sebagai gg.Pattern
+func (p patternImage) ColorAt(x, y int) color.Color { return p.At(x, y) }
+
+func (p patternImage) At(x, y int) color.Color {
+	x, y = x-p.offset.X, y-p.offset.Y
+	if p.tile.X > 0 {
+		// modulo di pattern gg tidak menangani koordinat negatif (box yang diputar)
+		x, y = (x%p.tile.X+p.tile.X)%p.tile.X, (y%p.tile.Y+p.tile.Y)%p.tile.Y
+	}
+	return p.pattern.ColorAt(x, y)
+}
+
+// gambar pattern disimpan supaya tidak di-decode ulang setiap request
+var patternCache = struct {
+	sync.RWMutex
+	images map[string]image.Image
+}{images: make(map[string]image.Image)}
+
+func loadPattern(path string) (image.Image, error) {
+	path = filepath.Clean(path)
+
+	patternCache.RLock()
+	img, ok := patternCache.images[path]
+	patternCache.RUnlock()
+	if ok {
+		return img, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err = image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	if img.Bounds().Empty() {
+		return nil, fmt.Errorf("empty image: %s", path)
+	}
+
+	patternCache.Lock()
+	patternCache.images[path] = img
+	patternCache.Unlock()
+	return img, nil
+}
@@ -0,0 +1,88 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePaint(t *testing.T) {
+	p, err := ParsePaint("#ff0000")
+	require.NoError(t, err)
+	assert.Equal(t, image.NewUniform(color.RGBA{R: 255, A: 255}), p.source(0, 0, 10, 10))
+
+	p, err = ParsePaint("linear-gradient(45deg, #ff0000, #00ff00 30%, #0000ff)")
+	require.NoError(t, err)
+	assert.Equal(t, 45.0, p.angle)
+	assert.Equal(t, []colorStop{
+		{pos: 0, color: color.RGBA{R: 255, A: 255}},
+		{pos: 0.3, color: color.RGBA{G: 255, A: 255}},
+		{pos: 1, color: color.RGBA{B: 255, A: 255}},
+	}, p.stops)
+
+	for _, s := range []string{
+		"reddish",
+		"#ff00f",
+		"linear-gradient(#ff0000)",
+		"linear-gradient(abcdeg, #ff0000, #0000ff)",
+		"linear-gradient(#ff0000 60%, #0000ff 20%)",
+		"radial-gradient(#ff0000, #0000ff 150%)",
+		"conic-gradient(#ff0000, #0000ff)",
+		"url(does/not/exist.png)",
+	} {
+		_, err := ParsePaint(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestDrawTextBoxes_GradientAndPattern(t *testing.T) {
+	// huruf yang sangat tebal supaya ada pixel teks penuh di kiri dan kanan box
+	box := TextBox{Name: "t", Width: 300, Height: 100, Font: testFont, Size: 90, LineSpacing: 1, Align: "center"}
+	text := map[string]string{"t": "■■■■■■"}
+	inked := func(img image.Image, x0, x1 int) (r, b uint32) {
+		for y := 0; y < 100; y++ {
+			for x := x0; x < x1; x++ {
+				cr, _, cb, ca := img.At(x, y).RGBA()
+				if ca == 0xffff {
+					r, b = max(r, cr>>8), max(b, cb>>8)
+				}
+			}
+		}
+		return r, b
+	}
+
+	box.Color = "linear-gradient(#ff0000, #0000ff)"
+	img, err := DrawTextBoxes(image.NewRGBA(image.Rect(0, 0, 300, 100)), text, []TextBox{box}, TextOptions{FallbackFonts: []string{hebrewFont}})
+	require.NoError(t, err)
+	r, b := inked(img, 0, 60)
+	assert.Greater(t, r, b, "kiri box merah")
+	r, b = inked(img, 240, 300)
+	assert.Greater(t, b, r, "kanan box biru")
+
+	// pattern 2x1 merah dan biru diulang, jadi teks berisi kedua warna
+	pattern := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	pattern.Set(0, 0, color.RGBA{R: 255, A: 255})
+	pattern.Set(1, 0, color.RGBA{B: 255, A: 255})
+	path := filepath.Join(t.TempDir(), "pattern.png")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, png.Encode(f, pattern))
+	require.NoError(t, f.Close())
+
+	box.Color = "url(" + path + ")"
+	img, err = DrawTextBoxes(image.NewRGBA(image.Rect(0, 0, 300, 100)), text, []TextBox{box}, TextOptions{FallbackFonts: []string{hebrewFont}})
+	require.NoError(t, err)
+	r, b = inked(img, 0, 300)
+	assert.Equal(t, uint32(255), r)
+	assert.Equal(t, uint32(255), b)
+
+	box.Color = "#zzzzzz"
+	_, err = DrawTextBoxes(image.NewRGBA(image.Rect(0, 0, 300, 100)), text, []TextBox{box}, TextOptions{})
+	assert.Error(t, err)
+}
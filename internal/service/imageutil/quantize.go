@@ -0,0 +1,140 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+)
+
+// jumlah pixel maksimum yang dipakai untuk membangun palet, sisanya diambil secara merata
+const maxQuantizeSamples = 1 << 16
+
+// Quantize mengubah gambar menjadi paletted dengan palet median cut maksimal maxColors warna.
+// Pemetaan warna memakai cache 15-bit (5 bit per channel) supaya tidak mencari palet per pixel.
+func Quantize(img image.Image, maxColors int) *image.Paletted {
+	b := img.Bounds()
+	src, ok := img.(*image.RGBA)
+	if !ok {
+		src = image.NewRGBA(b)
+		draw.Draw(src, b, img, b.Min, draw.Src)
+	}
+
+	palette := medianCut(samplePixels(src, maxQuantizeSamples), maxColors)
+	dst := image.NewPaletted(b, palette)
+
+	cache := make([]int16, 1<<15)
+	for i := range cache {
+		cache[i] = -1
+	}
+
+	for y := 0; y < b.Dy(); y++ {
+		srcRow := src.Pix[y*src.Stride : y*src.Stride+b.Dx()*4]
+		dstRow := dst.Pix[y*dst.Stride : y*dst.Stride+b.Dx()]
+		for x := range dstRow {
+			r, g, bl := srcRow[x*4]>>3, srcRow[x*4+1]>>3, srcRow[x*4+2]>>3
+			key := int(r)<<10 | int(g)<<5 | int(bl)
+			if cache[key] < 0 {
+				// cari warna terdekat dari titik tengah bucket
+				cache[key] = int16(palette.Index(color.RGBA{R: r<<3 | 4, G: g<<3 | 4, B: bl<<3 | 4, A: 255}))
+			}
+			dstRow[x] = uint8(cache[key])
+		}
+	}
+
+	return dst
+}
+
+func samplePixels(img *image.RGBA, maxSamples int) []color.RGBA {
+	b := img.Bounds()
+	total := b.Dx() * b.Dy()
+	step := max(total/maxSamples, 1)
+
+	samples := make([]color.RGBA, 0, min(total, maxSamples)+1)
+	for i := 0; i < total; i += step {
+		off := (i/b.Dx())*img.Stride + (i%b.Dx())*4
+		samples = append(samples, color.RGBA{R: img.Pix[off], G: img.Pix[off+1], B: img.Pix[off+2], A: 255})
+	}
+	return samples
+}
+
+// colorBox sekumpulan warna beserta channel dengan rentang terlebar
+type colorBox struct {
+	colors  []color.RGBA
+	channel int
+	spread  int
+}
+
+func newColorBox(colors []color.RGBA) colorBox {
+	ch, spread := widestChannel(colors)
+	return colorBox{colors: colors, channel: ch, spread: spread}
+}
+
+// medianCut membagi box warna dengan rentang channel terlebar di median sampai jumlah box = maxColors
+func medianCut(colors []color.RGBA, maxColors int) color.Palette {
+	if len(colors) == 0 {
+		return color.Palette{color.Black}
+	}
+
+	boxes := []colorBox{newColorBox(colors)}
+	for len(boxes) < maxColors {
+		best := -1
+		for i, box := range boxes {
+			if len(box.colors) >= 2 && box.spread > 0 && (best < 0 || box.spread > boxes[best].spread) {
+				best = i
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		box := boxes[best]
+		sort.Slice(box.colors, func(i, j int) bool {
+			return channel(box.colors[i], box.channel) < channel(box.colors[j], box.channel)
+		})
+		mid := len(box.colors) / 2
+		boxes[best] = newColorBox(box.colors[:mid])
+		boxes = append(boxes, newColorBox(box.colors[mid:]))
+	}
+
+	palette := make(color.Palette, len(boxes))
+	for i, box := range boxes {
+		var r, g, b int
+		for _, c := range box.colors {
+			r += int(c.R)
+			g += int(c.G)
+			b += int(c.B)
+		}
+		n := len(box.colors)
+		palette[i] = color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: 255}
+	}
+	return palette
+}
+
+func widestChannel(colors []color.RGBA) (int, int) {
+	lo, hi := [3]uint8{255, 255, 255}, [3]uint8{}
+	for _, c := range colors {
+		lo[0], hi[0] = min(lo[0], c.R), max(hi[0], c.R)
+		lo[1], hi[1] = min(lo[1], c.G), max(hi[1], c.G)
+		lo[2], hi[2] = min(lo[2], c.B), max(hi[2], c.B)
+	}
+
+	best, bestRange := 0, 0
+	for ch := 0; ch < 3; ch++ {
+		if r := int(hi[ch]) - int(lo[ch]); r > bestRange {
+			best, bestRange = ch, r
+		}
+	}
+	return best, bestRange
+}
+
+func channel(c color.RGBA, ch int) uint8 {
+	switch ch {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	default:
+		return c.B
+	}
+}
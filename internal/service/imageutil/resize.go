@@ -4,12 +4,60 @@ import (
 	"fmt"
 	"github.com/disintegration/imaging"
 	"image"
+	"image/draw"
+	"math"
 )
 
 func ResizeWithLockRatio(img image.Image, width int) image.Image {
 	return imaging.Resize(img, width, 0, imaging.Lanczos)
 }
 
+func ResizeWithLockHeight(img image.Image, height int) image.Image {
+	return imaging.Resize(img, 0, height, imaging.Lanczos)
+}
+
+// ResizeWithLockFit resize dengan rasio terkunci supaya muat di dalam width x height
+func ResizeWithLockFit(img image.Image, width, height int) image.Image {
+	w, h := containSize(img.Bounds(), width, height)
+	return imaging.Resize(img, w, h, imaging.Lanczos)
+}
+
+// ResizeWithContain menampilkan seluruh gambar di dalam width x height, sisa area diisi background
+func ResizeWithContain(img image.Image, width, height int, bg Background) (image.Image, error) {
+	fitted := ResizeWithLockFit(img, width, height)
+
+	canvas := NewCanvas(width, height)
+	switch bg.Type {
+	case BackgroundTransparent, "":
+	case BackgroundColor:
+		c, err := ParseColor(bg.Color)
+		if err != nil {
+			return nil, err
+		}
+		draw.Draw(canvas, canvas.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	case BackgroundBlur:
+		sigma := bg.Blur
+		if sigma == 0 {
+			sigma = defaultBackgroundBlur
+		}
+		blurred := imaging.Blur(ResizeWithFill(img, width, height), sigma)
+		draw.Draw(canvas, canvas.Bounds(), blurred, image.Point{}, draw.Src)
+	default:
+		return nil, fmt.Errorf("invalid background type: %s", bg.Type)
+	}
+
+	fb := fitted.Bounds()
+	x, y := (width-fb.Dx())/2, (height-fb.Dy())/2
+	draw.Draw(canvas, image.Rect(x, y, x+fb.Dx(), y+fb.Dy()), fitted, fb.Min, draw.Over)
+	return canvas, nil
+}
+
+// containSize ukuran terbesar dengan rasio sumber yang muat di dalam width x height
+func containSize(b image.Rectangle, width, height int) (int, int) {
+	scale := math.Min(float64(width)/float64(b.Dx()), float64(height)/float64(b.Dy()))
+	return max(int(math.Round(float64(b.Dx())*scale)), 1), max(int(math.Round(float64(b.Dy())*scale)), 1)
+}
+
 func ResizeWithoutLockRatio(img image.Image, width, height int) image.Image {
 	return imaging.Resize(img, width, height, imaging.Lanczos)
 }
@@ -18,24 +66,210 @@ func ResizeWithFill(img image.Image, width, height int) image.Image {
 	return imaging.Fill(img, width, height, imaging.Center, imaging.Lanczos)
 }
 
+// ResizeWithFillAt sama seperti ResizeWithFill tapi area crop dipusatkan pada titik fokus
+func ResizeWithFillAt(img image.Image, width, height int, focus FocalPoint) image.Image {
+	b := img.Bounds()
+	cw, ch := fillCropSize(b.Dx(), b.Dy(), width, height)
+
+	x := clampInt(int(focus.X*float64(b.Dx()))-cw/2, 0, b.Dx()-cw)
+	y := clampInt(int(focus.Y*float64(b.Dy()))-ch/2, 0, b.Dy()-ch)
+
+	cropped := Crop(img, b.Min.X+x, b.Min.Y+y, cw, ch)
+	return imaging.Resize(cropped, width, height, imaging.Lanczos)
+}
+
+// ResizeWithSmartFill memilih area crop berdasarkan saliency (edge energy dan entropy)
+func ResizeWithSmartFill(img image.Image, width, height int) image.Image {
+	return ResizeWithFillAt(img, width, height, SmartFocus(img, width, height))
+}
+
+// fillCropSize ukuran crop terbesar pada gambar sumber dengan rasio target
+func fillCropSize(srcW, srcH, width, height int) (int, int) {
+	cw, ch := srcW, srcW*height/width
+	if ch > srcH {
+		cw, ch = srcH*width/height, srcH
+	}
+	return max(cw, 1), max(ch, 1)
+}
+
+func clampInt(v, lo, hi int) int {
+	if v > hi {
+		v = hi
+	}
+	if v < lo {
+		v = lo
+	}
+	return v
+}
+
 type ResizeMode string
 
 const (
-	LockRatio ResizeMode = "lock"    // 1:1 ratio
-	Stretch   ResizeMode = "stretch" // no lock aspect ratio
-	Fill      ResizeMode = "fill"    // resize with fill
+	LockRatio  ResizeMode = "lock"        // 1:1 ratio, dikunci ke width
+	LockWidth  ResizeMode = "lock_width"  // alias "lock"
+	LockHeight ResizeMode = "lock_height" // 1:1 ratio, dikunci ke height
+	LockFit    ResizeMode = "lock_fit"    // 1:1 ratio, muat di dalam width dan height
+	Stretch    ResizeMode = "stretch"     // no lock aspect ratio
+	Fill       ResizeMode = "fill"        // resize with fill
+	Smart      ResizeMode = "smart"       // fill dengan crop otomatis berdasarkan saliency
+	Contain    ResizeMode = "contain"     // seluruh gambar terlihat, sisa area diisi background
 )
 
+type BackgroundType string
+
+const (
+	BackgroundTransparent BackgroundType = "transparent"
+	BackgroundColor       BackgroundType = "color"
+	BackgroundBlur        BackgroundType = "blur" // salinan gambar yang di-blur
+
+	defaultBackgroundBlur = 20
+)
+
+// Background isi area kosong untuk mode contain
+type Background struct {
+	Type  BackgroundType `json:"type"`
+	Color string         `json:"color,omitempty"`
+	Blur  float64        `json:"blur,omitempty"` // sigma, default 20
+}
+
+func (b Background) Validate() error {
+	switch b.Type {
+	case "", BackgroundTransparent:
+		return nil
+	case BackgroundColor:
+		_, err := ParseColor(b.Color)
+		return err
+	case BackgroundBlur:
+		if b.Blur < 0 || b.Blur > maxFilterSigma {
+			return fmt.Errorf("background blur must be between 0 and %d", maxFilterSigma)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid background type: %s", b.Type)
+	}
+}
+
+// FocalPoint titik fokus ternormalisasi (0..1) pada gambar sumber
+type FocalPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// CropRect area crop ternormalisasi (0..1) pada gambar sumber
+type CropRect struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+type ResizeOptions struct {
+	Focus      *FocalPoint // dipakai oleh mode fill dan smart
+	Crop       *CropRect   // crop manual sebelum resize, untuk semua mode
+	Background *Background // dipakai oleh mode contain, default transparan
+}
+
+func (f FocalPoint) Validate() error {
+	if f.X < 0 || f.X > 1 || f.Y < 0 || f.Y > 1 {
+		return fmt.Errorf("focus must be between 0 and 1")
+	}
+	return nil
+}
+
+func (c CropRect) Validate() error {
+	if c.X < 0 || c.Y < 0 || c.Width <= 0 || c.Height <= 0 || c.X+c.Width > 1 || c.Y+c.Height > 1 {
+		return fmt.Errorf("crop must be a normalised rectangle inside the image")
+	}
+	return nil
+}
+
 // ResizeWithMode resize with custom user mode
 func ResizeWithMode(img image.Image, mode ResizeMode, width, height int) (image.Image, error) {
+	return ResizeWithOptions(img, mode, width, height, ResizeOptions{})
+}
+
+// ResizeWithOptions resize dengan mode user plus crop manual / titik fokus
+func ResizeWithOptions(img image.Image, mode ResizeMode, width, height int, opts ResizeOptions) (image.Image, error) {
+	if opts.Crop != nil {
+		if err := opts.Crop.Validate(); err != nil {
+			return nil, err
+		}
+		img = cropNormalized(img, *opts.Crop)
+	}
+	if opts.Focus != nil {
+		if err := opts.Focus.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Background != nil {
+		if err := opts.Background.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	switch mode {
-	case LockRatio:
+	case LockRatio, LockWidth:
 		return ResizeWithLockRatio(img, width), nil
+	case LockHeight:
+		return ResizeWithLockHeight(img, height), nil
+	case LockFit:
+		return ResizeWithLockFit(img, width, height), nil
+	case Contain:
+		var bg Background
+		if opts.Background != nil {
+			bg = *opts.Background
+		}
+		return ResizeWithContain(img, width, height, bg)
 	case Stretch:
 		return ResizeWithoutLockRatio(img, width, height), nil
 	case Fill:
+		if opts.Focus != nil {
+			return ResizeWithFillAt(img, width, height, *opts.Focus), nil
+		}
 		return ResizeWithFill(img, width, height), nil
+	case Smart:
+		if opts.Focus != nil {
+			return ResizeWithFillAt(img, width, height, *opts.Focus), nil
+		}
+		return ResizeWithSmartFill(img, width, height), nil
 	default:
 		return nil, fmt.Errorf("invalid resize mode: %s", mode)
 	}
 }
+
+// ResizeFrames resize semua frame animasi dengan pengaturan yang sama. Untuk mode smart tanpa
+// titik fokus, fokus dihitung sekali dari frame pertama supaya crop tidak bergeser antar frame.
+func ResizeFrames(frames []image.Image, mode ResizeMode, width, height int, opts ResizeOptions) ([]image.Image, error) {
+	if mode == Smart && opts.Focus == nil && len(frames) > 1 {
+		first := frames[0]
+		if opts.Crop != nil {
+			if err := opts.Crop.Validate(); err != nil {
+				return nil, err
+			}
+			first = cropNormalized(first, *opts.Crop)
+		}
+		focus := SmartFocus(first, width, height)
+		opts.Focus = &focus
+	}
+
+	resized := make([]image.Image, len(frames))
+	for i, frame := range frames {
+		img, err := ResizeWithOptions(frame, mode, width, height, opts)
+		if err != nil {
+			return nil, err
+		}
+		resized[i] = img
+	}
+	return resized, nil
+}
+
+// cropNormalized crop dengan koordinat relatif (0..1) terhadap ukuran gambar
+func cropNormalized(img image.Image, c CropRect) image.Image {
+	b := img.Bounds()
+	return Crop(img,
+		b.Min.X+int(c.X*float64(b.Dx())),
+		b.Min.Y+int(c.Y*float64(b.Dy())),
+		max(int(c.Width*float64(b.Dx())), 1),
+		max(int(c.Height*float64(b.Dy())), 1),
+	)
+}
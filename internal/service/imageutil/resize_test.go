@@ -0,0 +1,72 @@
+package imageutil
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResizeWithOptions_FocusAndCrop(t *testing.T) {
+	img := generateDetailImage(1200, 600, 1000, 300, 100)
+
+	out, err := ResizeWithOptions(img, Fill, 300, 300, ResizeOptions{Focus: &FocalPoint{X: 1, Y: 0.5}})
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 300, 300), out.Bounds())
+
+	out, err = ResizeWithOptions(img, Stretch, 100, 50, ResizeOptions{Crop: &CropRect{X: 0.5, Y: 0, Width: 0.5, Height: 1}})
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 100, 50), out.Bounds())
+
+	_, err = ResizeWithOptions(img, Fill, 300, 300, ResizeOptions{Crop: &CropRect{X: 0.8, Y: 0, Width: 0.5, Height: 1}})
+	assert.Error(t, err)
+
+	_, err = ResizeWithOptions(img, Smart, 300, 300, ResizeOptions{Focus: &FocalPoint{X: 2}})
+	assert.Error(t, err)
+}
+
+func TestResizeWithMode_LockVariants(t *testing.T) {
+	img := generateDetailImage(1200, 600, 600, 300, 50)
+
+	testCases := []struct {
+		mode     ResizeMode
+		expected image.Rectangle
+	}{
+		{LockRatio, image.Rect(0, 0, 400, 200)},
+		{LockWidth, image.Rect(0, 0, 400, 200)},
+		{LockHeight, image.Rect(0, 0, 800, 400)},
+		{LockFit, image.Rect(0, 0, 400, 200)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			out, err := ResizeWithMode(img, tc.mode, 400, 400)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, out.Bounds())
+		})
+	}
+}
+
+func TestResizeWithContain_Backgrounds(t *testing.T) {
+	img := generateDetailImage(1200, 600, 600, 300, 50)
+
+	out, err := ResizeWithOptions(img, Contain, 400, 400, ResizeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 400, 400), out.Bounds())
+	_, _, _, a := out.At(200, 10).RGBA()
+	assert.Zero(t, a, "letterbox should be transparent by default")
+
+	out, err = ResizeWithOptions(img, Contain, 400, 400, ResizeOptions{Background: &Background{Type: BackgroundColor, Color: "#ff0000"}})
+	require.NoError(t, err)
+	r, g, b, _ := out.At(200, 10).RGBA()
+	assert.Equal(t, []uint32{0xffff, 0, 0}, []uint32{r, g, b})
+
+	out, err = ResizeWithOptions(img, Contain, 400, 400, ResizeOptions{Background: &Background{Type: BackgroundBlur}})
+	require.NoError(t, err)
+	_, _, _, a = out.At(200, 10).RGBA()
+	assert.Equal(t, uint32(0xffff), a)
+
+	_, err = ResizeWithOptions(img, Contain, 400, 400, ResizeOptions{Background: &Background{Type: "pattern"}})
+	assert.Error(t, err)
+}
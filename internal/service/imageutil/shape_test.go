@@ -0,0 +1,59 @@
+package imageutil
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrawShape_Ellipse(t *testing.T) {
+	img, err := DrawShape(100, 60, Shape{Kind: ShapeEllipse, X: 10, Y: 10, Width: 80, Height: 40, Color: "#ff0000", Stroke: "#0000ff", StrokeWidth: 4})
+	require.NoError(t, err)
+
+	assert.Equal(t, color.RGBA{R: 0xff, A: 0xff}, img.At(50, 30), "tengah terisi")
+	assert.Equal(t, color.RGBA{B: 0xff, A: 0xff}, img.At(10, 30), "tepi kiri stroke")
+	assert.Equal(t, color.RGBA{}, img.At(12, 12), "sudut area di luar elips")
+}
+
+func TestDrawShape_Line(t *testing.T) {
+	img, err := DrawShape(100, 50, Shape{Kind: ShapeLine, X: 10, Y: 20, Width: 80, Stroke: "#00ff00", StrokeWidth: 6})
+	require.NoError(t, err)
+
+	assert.Equal(t, color.RGBA{G: 0xff, A: 0xff}, img.At(50, 20))
+	assert.Equal(t, color.RGBA{G: 0xff, A: 0xff}, img.At(50, 22))
+	assert.Equal(t, color.RGBA{}, img.At(50, 26))
+	assert.Equal(t, color.RGBA{}, img.At(95, 20), "di luar ujung garis")
+}
+
+func TestDrawShape_GradientFill(t *testing.T) {
+	img, err := DrawShape(100, 100, Shape{Kind: ShapeRect, Width: 100, Height: 100, Color: "radial-gradient(#ffffff, #000000)"})
+	require.NoError(t, err)
+
+	center, _, _, _ := img.At(50, 50).RGBA()
+	corner, _, _, _ := img.At(2, 2).RGBA()
+	assert.Greater(t, center, uint32(0xf000))
+	assert.Less(t, corner, uint32(0x1000))
+
+	img, err = DrawShape(100, 10, Shape{Kind: ShapeRoundedRect, Width: 100, Height: 10, Radius: 4, Color: "linear-gradient(#000000, #ffffff)"})
+	require.NoError(t, err)
+	left, _, _, _ := img.At(10, 5).RGBA()
+	right, _, _, _ := img.At(90, 5).RGBA()
+	assert.Less(t, left, right)
+}
+
+func TestShape_Validate(t *testing.T) {
+	for _, s := range []Shape{
+		{Kind: ShapeRect},
+		{Kind: ShapeEllipse, Color: "nope"},
+		{Kind: ShapeLine},
+		{Kind: ShapeLine, Stroke: "#fff", Color: "#000"},
+		{Kind: ShapeRect, Color: "#fff", Stroke: "linear-gradient(#fff)"},
+		{Kind: ShapeRect, Stroke: "#fff", StrokeWidth: -1},
+		{Kind: "star", Color: "#fff"},
+	} {
+		assert.Error(t, s.Validate(), "%+v", s)
+	}
+	assert.NoError(t, Shape{Kind: ShapeRoundedRect, Stroke: "#fff"}.Validate(), "stroke saja tanpa isi")
+}
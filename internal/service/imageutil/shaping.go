@@ -0,0 +1,282 @@
+package imageutil
+
+import (
+	"image"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-text/typesetting/bidi"
+	"github.com/go-text/typesetting/di"
+	otfont "github.com/go-text/typesetting/font"
+	ot "github.com/go-text/typesetting/font/opentype"
+	"github.com/go-text/typesetting/language"
+	"github.com/go-text/typesetting/shaping"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// arah teks text box
+const (
+	DirectionAuto = "auto" // default, mengikuti huruf pertama yang punya arah di setiap paragraf
+	DirectionLTR  = "ltr"
+	DirectionRTL  = "rtl"
+)
+
+// complexScripts aksara yang bentuk hurufnya bergantung pada huruf di sekitarnya
+var complexScripts = map[language.Script]bool{
+	language.Arabic: true, language.Hebrew: true, language.Syriac: true, language.Thaana: true,
+	language.Nko: true, language.Devanagari: true, language.Bengali: true, language.Gurmukhi: true,
+	language.Gujarati: true, language.Oriya: true, language.Tamil: true, language.Telugu: true,
+	language.Kannada: true, language.Malayalam: true, language.Sinhala: true, language.Thai: true,
+	language.Lao: true, language.Tibetan: true, language.Myanmar: true, language.Khmer: true,
+	language.Mongolian: true, language.Inherited: true,
+}
+
+// cursiveScripts aksara yang hurufnya bersambung, letter spacing tidak dipakai supaya sambungannya tidak putus
+var cursiveScripts = map[language.Script]bool{
+	language.Arabic: true, language.Syriac: true, language.Nko: true, language.Mongolian: true,
+}
+
+func bidiDirection(direction string) bidi.Direction {
+	switch direction {
+	case DirectionLTR:
+		return bidi.LeftToRight
+	case DirectionRTL:
+		return bidi.RightToLeft
+	}
+	return bidi.Neutral
+}
+
+// shapeParagraph menjalankan algoritma bidi untuk satu paragraf, memecah piece yang berisi
+// beberapa arah atau aksara, lalu melakukan shaping untuk piece kanan ke kiri atau beraksara
+// kompleks. Piece lain tetap digambar per rune. Mengembalikan true bila arah paragraf kanan ke kiri.
+func shapeParagraph(tokens []textToken, direction string, shaper *shaping.HarfbuzzShaper) bool {
+	var text []rune
+	for _, tok := range tokens {
+		for _, p := range tok.pieces {
+			text = append(text, []rune(p.text)...)
+		}
+	}
+	if len(text) == 0 {
+		return direction == DirectionRTL
+	}
+
+	levels, rtl := paragraphLevels(text, direction)
+
+	pos := 0
+	for i := range tokens {
+		var pieces []textPiece
+		for _, p := range tokens[i].pieces {
+			n := utf8.RuneCountInString(p.text)
+			pieces = append(pieces, splitPiece(p, text, levels, pos, pos+n, shaper)...)
+			pos += n
+		}
+		tokens[i].pieces = pieces
+	}
+	return rtl
+}
+
+// paragraphLevels level bidi setiap huruf dan arah paragraf. Run dari go-text hanya membedakan
+// ganjil dan genap (level dari huruf pertama run), jadi di paragraf kiri ke kanan huruf di run
+// genap dikembalikan ke level 0, kecuali angka setelah teks kanan ke kiri yang tetap level 2.
+func paragraphLevels(text []rune, direction string) ([]bidi.Level, bool) {
+	var para bidi.Paragraph
+	runs := para.Segment(text, bidiDirection(direction))
+	levels := make([]bidi.Level, len(text))
+	minLevel := bidi.Level(math.MaxInt8)
+	for i := 0; i < runs.NumRuns(); i++ {
+		run := runs.Run(i)
+		for j := run.Start; j < run.End; j++ {
+			levels[j] = run.Level
+		}
+		minLevel = min(minLevel, run.Level)
+	}
+
+	rtl := direction == DirectionRTL
+	if direction != DirectionLTR && direction != DirectionRTL {
+		// dengan arah auto tidak ada huruf yang levelnya di bawah level paragraf
+		rtl = minLevel%2 == 1
+	}
+	if rtl {
+		return levels, rtl
+	}
+
+	for i, r := range text {
+		if levels[i]%2 == 1 {
+			continue
+		}
+		levels[i] = 0
+		switch {
+		case r >= 0x0660 && r <= 0x066c:
+			// angka Arab-Indic selalu level 2
+			levels[i] = 2
+		case i > 0 && levels[i-1] > 0 && isBidiNumber(text, i):
+			levels[i] = 2
+		}
+	}
+	return levels, rtl
+}
+
+// isBidiNumber true untuk angka, atau pemisah angka (mis. "1,5") yang diapit dua angka
+func isBidiNumber(text []rune, i int) bool {
+	if unicode.IsDigit(text[i]) {
+		return true
+	}
+	return strings.ContainsRune(".,:/+-", text[i]) && i+1 < len(text) && unicode.IsDigit(text[i+1]) && i > 0 && unicode.IsDigit(text[i-1])
+}
+
+// splitPiece memecah piece text[start:end] setiap kali level bidi atau aksaranya berganti.
+// Huruf tanpa aksara (spasi, tanda baca, angka) ikut aksara sebelumnya.
+func splitPiece(p textPiece, text []rune, levels []bidi.Level, start, end int, shaper *shaping.HarfbuzzShaper) []textPiece {
+	if p.emoji != nil {
+		p.level = levels[start]
+		return []textPiece{p}
+	}
+
+	var out []textPiece
+	from, script := start, language.Common
+	for i := start; i <= end; i++ {
+		var s language.Script
+		if i < end {
+			s = language.LookupScript(text[i])
+			if i == from || (levels[i] == levels[from] && (!s.Strong() || !script.Strong() || s == script)) {
+				if s.Strong() && !script.Strong() {
+					script = s
+				}
+				continue
+			}
+		}
+
+		piece := textPiece{text: string(text[from:i]), style: p.style, level: levels[from]}
+		if piece.level%2 == 1 || complexScripts[script] {
+			dir := di.DirectionLTR
+			if piece.level%2 == 1 {
+				dir = di.DirectionRTL
+			}
+			shaped := shaper.Shape(shaping.Input{
+				Text:      text,
+				RunStart:  from,
+				RunEnd:    i,
+				Direction: dir,
+				Face:      p.style.face.shape,
+				Size:      p.style.face.size,
+				Script:    script,
+			})
+			piece.glyphs = shaped.Glyphs
+			piece.cursive = cursiveScripts[script]
+		}
+		out = append(out, piece)
+
+		from, script = i, language.Common
+		if s.Strong() {
+			script = s
+		}
+	}
+	return out
+}
+
+// visualOrder menyusun ulang piece satu baris dari urutan logis ke urutan tampil
+// (aturan L2 Unicode bidi). Glyph di dalam piece hasil shaping sudah dalam urutan tampil.
+func visualOrder(tokens []textToken) []textToken {
+	type item struct {
+		tok   int
+		piece textPiece
+	}
+	var items []item
+	var maxLevel bidi.Level
+	minLevel := bidi.Level(math.MaxInt8)
+	for i, tok := range tokens {
+		for _, p := range tok.pieces {
+			items = append(items, item{tok: i, piece: p})
+			maxLevel, minLevel = max(maxLevel, p.level), min(minLevel, p.level)
+		}
+	}
+	if maxLevel == 0 {
+		return tokens
+	}
+
+	// balik setiap deret dengan level >= lvl, dari level tertinggi sampai level ganjil terendah
+	for lvl := maxLevel; lvl >= minLevel|1; lvl-- {
+		for i := 0; i < len(items); {
+			if items[i].piece.level < lvl {
+				i++
+				continue
+			}
+			j := i
+			for j < len(items) && items[j].piece.level >= lvl {
+				j++
+			}
+			slices.Reverse(items[i:j])
+			i = j
+		}
+	}
+
+	out := make([]textToken, 0, len(tokens))
+	for i, it := range items {
+		if i == 0 || it.tok != items[i-1].tok {
+			out = append(out, textToken{space: tokens[it.tok].space})
+		}
+		last := &out[len(out)-1]
+		last.pieces = append(last.pieces, it.piece)
+	}
+	return out
+}
+
+// drawShapedGlyph menggambar glyph hasil shaping dengan dot di baseline kiri glyph
+func drawShapedGlyph(dst draw.Image, style *textStyle, g *shaping.Glyph, dot fixed.Point26_6) {
+	outline, ok := style.face.shape.GlyphData(g.GlyphID).(otfont.GlyphOutline)
+	if !ok || len(outline.Segments) == 0 {
+		return
+	}
+
+	scale := float32(style.face.size) / 64 / float32(style.face.shape.Upem())
+	x, y := float32(dot.X+g.XOffset)/64, float32(dot.Y-g.YOffset)/64
+	point := func(p ot.SegmentPoint) (float32, float32) {
+		return x + p.X*scale, y - p.Y*scale
+	}
+
+	minX, minY := float32(math.Inf(1)), float32(math.Inf(1))
+	maxX, maxY := float32(math.Inf(-1)), float32(math.Inf(-1))
+	for _, s := range outline.Segments {
+		for _, p := range s.ArgsSlice() {
+			px, py := point(p)
+			minX, minY, maxX, maxY = min(minX, px), min(minY, py), max(maxX, px), max(maxY, py)
+		}
+	}
+	bounds := image.Rect(int(math.Floor(float64(minX))), int(math.Floor(float64(minY))), int(math.Ceil(float64(maxX))), int(math.Ceil(float64(maxY))))
+	if bounds.Empty() {
+		return
+	}
+
+	var r vector.Rasterizer
+	r.Reset(bounds.Dx(), bounds.Dy())
+	ox, oy := float32(bounds.Min.X), float32(bounds.Min.Y)
+	for _, s := range outline.Segments {
+		x1, y1 := point(s.Args[0])
+		x2, y2 := point(s.Args[1])
+		x3, y3 := point(s.Args[2])
+		switch s.Op {
+		case ot.SegmentOpMoveTo:
+			r.ClosePath()
+			r.MoveTo(x1-ox, y1-oy)
+		case ot.SegmentOpLineTo:
+			r.LineTo(x1-ox, y1-oy)
+		case ot.SegmentOpQuadTo:
+			r.QuadTo(x1-ox, y1-oy, x2-ox, y2-oy)
+		case ot.SegmentOpCubeTo:
+			r.CubeTo(x1-ox, y1-oy, x2-ox, y2-oy, x3-ox, y3-oy)
+		}
+	}
+	r.ClosePath()
+
+	mask := image.NewAlpha(r.Bounds())
+	r.Draw(mask, mask.Rect, image.Opaque, image.Point{})
+
+	for b := 0; b <= style.boldness; b++ {
+		draw.DrawMask(dst, bounds.Add(image.Pt(b, 0)), style.fill, bounds.Min, mask, image.Point{}, draw.Over)
+	}
+}
@@ -0,0 +1,145 @@
+package imageutil
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "tulis ulang file golden di testdata")
+
+const (
+	arabicFont = "../../../assets/fonts/NotoSansArabic-Regular.ttf"
+	hebrewFont = "../../../assets/fonts/DejaVuSans.ttf"
+)
+
+func TestDrawTextBoxes_Golden(t *testing.T) {
+	tests := []struct {
+		name string
+		box  TextBox
+		text string
+	}{
+		{"arabic", TextBox{Font: arabicFont, Size: 40}, "مرحبا بالعالم **العربي** ٢٠٢٤"},
+		{"arabic_wrapped", TextBox{Font: arabicFont, Size: 32}, "عاجل: أمطار غزيرة تضرب المدينة صباح اليوم"},
+		{"hebrew", TextBox{Font: hebrewFont, Size: 36}, "שלום עולם (בדיקה) 2024!"},
+		{"mixed_ltr", TextBox{Font: hebrewFont, Size: 28, Direction: DirectionLTR}, "Breaking: مرحبا بالعالم 123 and שלום"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := image.NewRGBA(image.Rect(0, 0, 480, 140))
+			draw.Draw(base, base.Rect, image.White, image.Point{}, draw.Src)
+
+			box := tt.box
+			box.Name, box.Width, box.Height, box.Padding, box.Color, box.LineSpacing = "t", 480, 140, 10, "#000000", 1.2
+			img, err := DrawTextBoxes(base, map[string]string{"t": tt.text}, []TextBox{box}, TextOptions{})
+			require.NoError(t, err)
+
+			path := filepath.Join("testdata", tt.name+".png")
+			if *updateGolden {
+				require.NoError(t, os.MkdirAll("testdata", 0o755))
+				f, err := os.Create(path)
+				require.NoError(t, err)
+				require.NoError(t, png.Encode(f, img))
+				require.NoError(t, f.Close())
+			}
+
+			f, err := os.Open(path)
+			require.NoError(t, err, "jalankan go test -run Golden -update untuk membuat file golden")
+			defer f.Close()
+			want, err := png.Decode(f)
+			require.NoError(t, err)
+
+			// toleransi kecil untuk perbedaan pembulatan floating point antar arsitektur
+			require.Equal(t, want.Bounds(), img.Bounds())
+			diff := 0
+			for y := 0; y < want.Bounds().Dy(); y++ {
+				for x := 0; x < want.Bounds().Dx(); x++ {
+					if colorDistance(want.At(x, y), img.At(x, y)) > 16 {
+						diff++
+					}
+				}
+			}
+			assert.LessOrEqual(t, diff, 20, "%d pixel berbeda dari %s", diff, path)
+		})
+	}
+}
+
+func colorDistance(a, b color.Color) uint32 {
+	ar, ag, ab, _ := a.RGBA()
+	br, bg, bb, _ := b.RGBA()
+	d := func(x, y uint32) uint32 {
+		if x > y {
+			return (x - y) >> 8
+		}
+		return (y - x) >> 8
+	}
+	return max(d(ar, br), d(ag, bg), d(ab, bb))
+}
+
+func visualText(line textLine) []string {
+	var out []string
+	for _, tok := range line.tokens {
+		if !tok.space {
+			out = append(out, lineText(textLine{tokens: []textToken{tok}}))
+		}
+	}
+	return out
+}
+
+func TestLayoutParagraphs_Bidi(t *testing.T) {
+	face, err := loadFontFace(hebrewFont, 30)
+	require.NoError(t, err)
+	style := &textStyle{face: face, fill: image.Black}
+	layout := func(text, direction string) textLine {
+		lines := layoutParagraphs([]textPiece{{text: text, style: style}}, WrapNone, direction, 1000)
+		require.Len(t, lines, 1)
+		return lines[0]
+	}
+
+	line := layout("שלום עולם", DirectionAuto)
+	assert.True(t, line.rtl)
+	assert.Equal(t, []string{"עולם", "שלום"}, visualText(line))
+
+	line = layout("news שלום עולם today", DirectionAuto)
+	assert.False(t, line.rtl)
+	assert.Equal(t, []string{"news", "עולם", "שלום", "today"}, visualText(line))
+
+	// angka setelah teks Arab tetap kiri ke kanan dan ikut terbalik bersama teks Arab
+	line = layout("Breaking: مرحبا بالعالم 123 and", DirectionLTR)
+	assert.Equal(t, []string{"Breaking:", "123", "بالعالم", "مرحبا", "and"}, visualText(line))
+
+	line = layout("hello world", DirectionRTL)
+	assert.True(t, line.rtl)
+	assert.Equal(t, []string{"hello", "world"}, visualText(line))
+}
+
+func TestLayoutParagraphs_ArabicShaping(t *testing.T) {
+	face, err := loadFontFace(arabicFont, 30)
+	require.NoError(t, err)
+	style := &textStyle{face: face, fill: image.Black}
+
+	lines := layoutParagraphs([]textPiece{{text: "ببب", style: style}}, WrapNone, DirectionAuto, 1000)
+	require.Len(t, lines, 1)
+	piece := lines[0].tokens[0].pieces[0]
+	require.Len(t, piece.glyphs, 3)
+
+	// huruf yang bersambung memakai bentuk awal, tengah dan akhir, bukan bentuk tunggal
+	isolated, ok := face.shape.NominalGlyph('ب')
+	require.True(t, ok)
+	for _, g := range piece.glyphs {
+		assert.NotEqual(t, isolated, g.GlyphID)
+	}
+
+	// teks Latin tetap digambar per rune seperti sebelumnya
+	lines = layoutParagraphs([]textPiece{{text: "abc", style: style}}, WrapNone, DirectionAuto, 1000)
+	assert.Nil(t, lines[0].tokens[0].pieces[0].glyphs)
+}
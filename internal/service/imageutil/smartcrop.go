@@ -0,0 +1,159 @@
+package imageutil
+
+import (
+	"image"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	saliencySize     = 256 // sisi terpanjang gambar saat analisis
+	saliencyCell     = 16  // ukuran cell untuk perhitungan entropy
+	saliencyBins     = 16
+	edgeWeight       = 0.6
+	entropyWeight    = 0.4
+	centerBiasWeight = 0.15 // preferensi kecil ke tengah saat skor hampir sama
+)
+
+// SmartFocus mencari titik fokus untuk crop fill dengan rasio width x height.
+// Saliency dihitung dari edge energy dan entropy luminance lokal, tanpa model ML.
+func SmartFocus(img image.Image, width, height int) FocalPoint {
+	center := FocalPoint{X: 0.5, Y: 0.5}
+	if width <= 0 || height <= 0 {
+		return center
+	}
+
+	small := imaging.Fit(img, saliencySize, saliencySize, imaging.Linear)
+	sw, sh := small.Rect.Dx(), small.Rect.Dy()
+	if sw < 3 || sh < 3 {
+		return center
+	}
+
+	sal := saliencyMap(small)
+	cw, ch := fillCropSize(sw, sh, width, height)
+
+	switch {
+	case cw < sw:
+		cols := make([]float64, sw)
+		for y := 0; y < sh; y++ {
+			for x := 0; x < sw; x++ {
+				cols[x] += sal[y*sw+x]
+			}
+		}
+		best := bestWindow(cols, cw)
+		center.X = (float64(best) + float64(cw)/2) / float64(sw)
+	case ch < sh:
+		rows := make([]float64, sh)
+		for y := 0; y < sh; y++ {
+			for x := 0; x < sw; x++ {
+				rows[y] += sal[y*sw+x]
+			}
+		}
+		best := bestWindow(rows, ch)
+		center.Y = (float64(best) + float64(ch)/2) / float64(sh)
+	}
+
+	return center
+}
+
+// bestWindow mencari posisi awal window sepanjang size dengan total saliency terbesar
+func bestWindow(sums []float64, size int) int {
+	n := len(sums)
+	if size >= n {
+		return 0
+	}
+
+	var total, window float64
+	for _, v := range sums {
+		total += v
+	}
+	for i := 0; i < size; i++ {
+		window += sums[i]
+	}
+
+	span := float64(n - size)
+	best, bestScore := 0, math.Inf(-1)
+	for start := 0; start+size <= n; start++ {
+		if start > 0 {
+			window += sums[start+size-1] - sums[start-1]
+		}
+
+		score := window
+		if total > 0 {
+			score /= total
+		}
+		offset := math.Abs(float64(start)-span/2) / (span / 2)
+		score -= centerBiasWeight * offset * float64(size) / float64(n)
+
+		if score > bestScore {
+			best, bestScore = start, score
+		}
+	}
+
+	return best
+}
+
+// saliencyMap menggabungkan edge energy per pixel dan entropy per cell, hasil 0..1
+func saliencyMap(img *image.NRGBA) []float64 {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	lum := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := img.PixOffset(x, y)
+			r, g, b := float64(img.Pix[i]), float64(img.Pix[i+1]), float64(img.Pix[i+2])
+			a := float64(img.Pix[i+3]) / 255
+			lum[y*w+x] = (0.299*r + 0.587*g + 0.114*b) * a
+		}
+	}
+
+	edges := make([]float64, w*h)
+	var maxEdge float64
+	for y := 1; y < h-1; y++ {
+		for x := 1; x < w-1; x++ {
+			dx := lum[y*w+x+1] - lum[y*w+x-1]
+			dy := lum[(y+1)*w+x] - lum[(y-1)*w+x]
+			e := math.Abs(dx) + math.Abs(dy)
+			edges[y*w+x] = e
+			maxEdge = math.Max(maxEdge, e)
+		}
+	}
+
+	sal := make([]float64, w*h)
+	maxEntropy := math.Log2(saliencyBins)
+	for cy := 0; cy < h; cy += saliencyCell {
+		for cx := 0; cx < w; cx += saliencyCell {
+			x1, y1 := min(cx+saliencyCell, w), min(cy+saliencyCell, h)
+
+			var hist [saliencyBins]float64
+			var count float64
+			for y := cy; y < y1; y++ {
+				for x := cx; x < x1; x++ {
+					hist[int(lum[y*w+x])*saliencyBins/256]++
+					count++
+				}
+			}
+
+			var entropy float64
+			for _, c := range hist {
+				if c > 0 {
+					p := c / count
+					entropy -= p * math.Log2(p)
+				}
+			}
+			entropy /= maxEntropy
+
+			for y := cy; y < y1; y++ {
+				for x := cx; x < x1; x++ {
+					e := 0.0
+					if maxEdge > 0 {
+						e = edges[y*w+x] / maxEdge
+					}
+					sal[y*w+x] = edgeWeight*e + entropyWeight*entropy
+				}
+			}
+		}
+	}
+
+	return sal
+}
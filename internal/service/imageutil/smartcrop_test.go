@@ -0,0 +1,57 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// generateDetailImage membuat gambar polos dengan area checkerboard di sekitar (cx, cy)
+func generateDetailImage(w, h, cx, cy, size int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{120, 140, 160, 255}
+			if abs(x-cx) < size && abs(y-cy) < size && (x/8+y/8)%2 == 0 {
+				c = color.RGBA{250, 250, 250, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func TestSmartFocus_FollowsDetailHorizontally(t *testing.T) {
+	img := generateDetailImage(1200, 600, 1000, 300, 100)
+
+	focus := SmartFocus(img, 400, 600)
+
+	assert.Greater(t, focus.X, 0.7)
+	assert.Equal(t, 0.5, focus.Y)
+}
+
+func TestSmartFocus_FollowsDetailVertically(t *testing.T) {
+	img := generateDetailImage(600, 1200, 300, 150, 100)
+
+	focus := SmartFocus(img, 600, 300)
+
+	assert.Equal(t, 0.5, focus.X)
+	assert.Less(t, focus.Y, 0.3)
+}
+
+func TestSmartFocus_FlatImageStaysCentered(t *testing.T) {
+	img := generateDetailImage(1200, 600, -1000, -1000, 0)
+
+	focus := SmartFocus(img, 600, 600)
+
+	assert.InDelta(t, 0.5, focus.X, 0.01)
+}
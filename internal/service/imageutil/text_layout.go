@@ -0,0 +1,319 @@
+package imageutil
+
+import (
+	"image"
+	"strings"
+	"unicode"
+
+	"github.com/go-text/typesetting/bidi"
+	"github.com/go-text/typesetting/shaping"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+	"golang.org/x/image/math/fixed"
+)
+
+// textStyle cara menggambar satu span: font, warna dan faux bold
+type textStyle struct {
+	face          *fontFace
+	fill          image.Image   // warna (*image.Uniform), gradient atau pattern dalam koordinat kanvas
+	boldness      int           // tebal tambahan dalam pixel bila tidak ada font bold, 0 = tidak ada
+	letterSpacing fixed.Int26_6 // jarak tambahan antar huruf
+}
+
+// textPiece potongan teks dengan satu gaya, satu level bidi dan satu aksara
+type textPiece struct {
+	text    string
+	style   *textStyle
+	level   bidi.Level      // level bidi, ganjil = kanan ke kiri
+	glyphs  []shaping.Glyph // hasil shaping dalam urutan tampil, nil = digambar per rune
+	cursive bool            // huruf bersambung, tanpa letter spacing di dalam piece
+	emoji   image.Image     // emoji berwarna, text berisi satu grapheme cluster
+}
+
+// textToken satu kata atau satu deret spasi, bisa terdiri dari beberapa gaya (mis. **Hal**o)
+type textToken struct {
+	pieces []textPiece
+	space  bool
+}
+
+// textLine satu baris hasil wrapping, tanpa spasi di awal dan akhir
+type textLine struct {
+	tokens []textToken
+	width  fixed.Int26_6
+	last   bool // baris terakhir paragraf, tidak di-justify
+	rtl    bool // arah paragraf kanan ke kiri
+}
+
+// mode wrapping text box
+const (
+	WrapWord = "word" // default, pindah baris di antara kata
+	WrapChar = "char" // pindah baris di antara huruf, untuk bahasa tanpa spasi
+	WrapNone = "none" // hanya pindah baris di \n
+)
+
+// layoutParagraphs memecah teks menjadi baris sesuai mode wrap. Setiap \n memulai paragraf baru
+// dengan arah sendiri (lihat DirectionAuto). Token di setiap baris sudah dalam urutan tampil.
+func layoutParagraphs(pieces []textPiece, wrap, direction string, width float64) []textLine {
+	var shaper shaping.HarfbuzzShaper
+	var lines []textLine
+	for _, para := range tokenize(pieces, wrap == WrapChar) {
+		rtl := shapeParagraph(para, direction, &shaper)
+
+		var paraLines []textLine
+		if wrap == WrapNone {
+			paraLines = []textLine{newTextLine(para)}
+		} else {
+			paraLines = wrapWords(para, width)
+		}
+		paraLines[len(paraLines)-1].last = true
+		for i := range paraLines {
+			paraLines[i].tokens = visualOrder(paraLines[i].tokens)
+			paraLines[i].rtl = rtl
+		}
+		lines = append(lines, paraLines...)
+	}
+	return lines
+}
+
+// tokenize memecah piece menjadi paragraf (dipisah \n) yang berisi token kata dan spasi.
+// Dengan splitChars setiap huruf menjadi token sendiri sehingga baris bisa dipotong di mana saja.
+func tokenize(pieces []textPiece, splitChars bool) [][]textToken {
+	paragraphs := [][]textToken{nil}
+
+	// teks piece terakhir dikumpulkan di builder dan baru disalin saat piece ditutup
+	var buf strings.Builder
+	var open *textPiece
+	closePiece := func() {
+		if open != nil {
+			open.text = buf.String()
+			buf.Reset()
+			open = nil
+		}
+	}
+
+	for _, p := range pieces {
+		if p.emoji != nil {
+			// emoji tidak dipecah meskipun splitChars, supaya urutan ZWJ tetap utuh
+			closePiece()
+			para := &paragraphs[len(paragraphs)-1]
+			if n := len(*para); n == 0 || (*para)[n-1].space || splitChars {
+				*para = append(*para, textToken{})
+			}
+			tok := &(*para)[len(*para)-1]
+			tok.pieces = append(tok.pieces, p)
+			continue
+		}
+
+		for _, r := range p.text {
+			para := &paragraphs[len(paragraphs)-1]
+			if r == '\r' {
+				continue
+			}
+			if r == '\n' {
+				closePiece()
+				paragraphs = append(paragraphs, nil)
+				continue
+			}
+
+			space := unicode.IsSpace(r)
+			if n := len(*para); n == 0 || (*para)[n-1].space != space || (splitChars && !space) {
+				closePiece()
+				*para = append(*para, textToken{space: space})
+			}
+
+			tok := &(*para)[len(*para)-1]
+			if open == nil || open.style != p.style {
+				closePiece()
+				tok.pieces = append(tok.pieces, textPiece{style: p.style})
+				open = &tok.pieces[len(tok.pieces)-1]
+			}
+			buf.WriteRune(r)
+		}
+	}
+	closePiece()
+
+	return paragraphs
+}
+
+// wrapWords memecah paragraf per kata supaya setiap baris muat di width,
+// aturannya sama dengan gg.WordWrap (kata yang lebih panjang dari width tetap satu baris)
+func wrapWords(tokens []textToken, width float64) []textLine {
+	var lines []textLine
+	var line []textToken
+
+	for _, tok := range tokens {
+		if tok.space {
+			if len(line) > 0 {
+				line = append(line, tok)
+			}
+			continue
+		}
+
+		candidate := append(line[:len(line):len(line)], tok)
+		if float64(measureTokens(candidate).Floor()) > width && len(line) > 0 {
+			lines = append(lines, newTextLine(line))
+			line = nil
+		}
+		line = append(line, tok)
+	}
+
+	return append(lines, newTextLine(line))
+}
+
+// justifySpace jarak tambahan per deret spasi supaya baris memenuhi width.
+// Baris terakhir paragraf dan baris tanpa spasi tetap rata kiri.
+func justifySpace(line textLine, width float64) fixed.Int26_6 {
+	if line.last {
+		return 0
+	}
+	gaps := 0
+	for _, tok := range line.tokens {
+		if tok.space {
+			gaps++
+		}
+	}
+	extra := fixed.Int26_6(width*64) - line.width
+	if gaps == 0 || extra <= 0 {
+		return 0
+	}
+	return extra / fixed.Int26_6(gaps)
+}
+
+func newTextLine(tokens []textToken) textLine {
+	for len(tokens) > 0 && tokens[len(tokens)-1].space {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return textLine{tokens: tokens, width: measureTokens(tokens)}
+}
+
+// measureTokens lebar token bila digambar berurutan, termasuk kerning antar huruf dengan font yang sama
+func measureTokens(tokens []textToken) fixed.Int26_6 {
+	var width fixed.Int26_6
+	walkGlyphs(tokens, func(g glyph, style *textStyle, kern fixed.Int26_6, _ *textToken) bool {
+		width += kern + g.advance(style)
+		return true
+	})
+	return width
+}
+
+// glyph satu glyph di baris: rune untuk teks biasa, glyph hasil shaping atau gambar emoji
+type glyph struct {
+	r      rune
+	shaped *shaping.Glyph
+	emoji  image.Image
+}
+
+// advance lebar glyph termasuk faux bold
+func (g glyph) advance(style *textStyle) fixed.Int26_6 {
+	switch {
+	case g.emoji != nil:
+		return emojiAdvance(style, g.emoji)
+	case g.shaped != nil:
+		return g.shaped.Advance + fixed.I(style.boldness)
+	}
+	adv, _ := style.face.GlyphAdvance(g.r)
+	return adv + fixed.I(style.boldness)
+}
+
+// walkGlyphs memanggil fn untuk setiap glyph beserta jarak terhadap glyph sebelumnya (kerning dan
+// letter spacing). fn mengembalikan false bila glyph tidak ada di font, sehingga tidak dipakai untuk
+// kerning berikutnya. Kerning glyph hasil shaping sudah termasuk di advance-nya.
+func walkGlyphs(tokens []textToken, fn func(g glyph, style *textStyle, kern fixed.Int26_6, tok *textToken) bool) {
+	started := false
+	prev := rune(-1)
+	var prevFace *fontFace
+	var cursiveTok *textToken // token yang piece terakhirnya berhuruf bersambung
+
+	for i := range tokens {
+		tok := &tokens[i]
+		for _, p := range tok.pieces {
+			if p.emoji != nil {
+				var kern fixed.Int26_6
+				if started {
+					kern = p.style.letterSpacing
+				}
+				fn(glyph{emoji: p.emoji}, p.style, kern, tok)
+				started, prev, cursiveTok = true, -1, nil
+				continue
+			}
+			if p.glyphs != nil {
+				for j := range p.glyphs {
+					g := &p.glyphs[j]
+					var kern fixed.Int26_6
+					// letter spacing hanya di antara cluster, huruf bersambung tidak direnggangkan
+					joined := p.cursive && (j > 0 || cursiveTok == tok)
+					if started && !joined && (j == 0 || g.ClusterIndex != p.glyphs[j-1].ClusterIndex) {
+						kern = p.style.letterSpacing
+					}
+					if fn(glyph{shaped: g}, p.style, kern, tok) {
+						started = true
+					}
+				}
+				prev, cursiveTok = -1, nil
+				if p.cursive {
+					cursiveTok = tok
+				}
+				continue
+			}
+			cursiveTok = nil
+
+			for _, r := range p.text {
+				var kern fixed.Int26_6
+				if started {
+					kern = p.style.letterSpacing
+					if prev >= 0 && prevFace == p.style.face {
+						kern += p.style.face.Kern(prev, r)
+					}
+				}
+				if fn(glyph{r: r}, p.style, kern, tok) {
+					started, prev, prevFace = true, r, p.style.face
+				}
+			}
+		}
+	}
+}
+
+// drawLine menggambar satu baris mulai dari dot (baseline kiri). spaceExtra ditambahkan ke setiap
+// deret spasi, dipakai untuk align justify.
+func drawLine(dst draw.Image, line textLine, dot fixed.Point26_6, spaceExtra fixed.Int26_6) {
+	var lastTok *textToken
+	walkGlyphs(line.tokens, func(g glyph, style *textStyle, kern fixed.Int26_6, tok *textToken) bool {
+		if tok != lastTok && tok.space {
+			dot.X += spaceExtra
+		}
+		lastTok = tok
+
+		dot.X += kern
+		switch {
+		case g.emoji != nil:
+			drawEmoji(dst, style, g.emoji, dot)
+			dot.X += g.advance(style)
+			return true
+		case g.shaped != nil:
+			drawShapedGlyph(dst, style, g.shaped, dot)
+			dot.X += g.advance(style)
+			return true
+		}
+
+		dr, mask, maskp, advance, ok := style.face.Glyph(dot, g.r)
+		if !ok {
+			return false
+		}
+
+		sr := dr.Sub(dr.Min)
+		for b := 0; b <= style.boldness; b++ {
+			if _, ok := style.fill.(*image.Uniform); !ok {
+				draw.DrawMask(dst, dr.Add(image.Pt(b, 0)), style.fill, dr.Min, mask, maskp, draw.Over)
+				continue
+			}
+			s2d := f64.Aff3{1, 0, float64(dr.Min.X + b), 0, 1, float64(dr.Min.Y)}
+			draw.BiLinear.Transform(dst, s2d, style.fill, sr, draw.Over, &draw.Options{
+				SrcMask:  mask,
+				SrcMaskP: maskp,
+			})
+		}
+
+		dot.X += advance + fixed.I(style.boldness)
+		return true
+	})
+}
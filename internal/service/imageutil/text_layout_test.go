@@ -0,0 +1,114 @@
+package imageutil
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/fogleman/gg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/math/fixed"
+)
+
+const testFont = "../../../assets/fonts/OpenSans-Bold.ttf"
+
+func lineText(line textLine) string {
+	var sb strings.Builder
+	for _, tok := range line.tokens {
+		for _, p := range tok.pieces {
+			sb.WriteString(p.text)
+		}
+	}
+	return sb.String()
+}
+
+func TestWrapWords_MatchesGG(t *testing.T) {
+	face, err := loadFontFace(testFont, 40)
+	require.NoError(t, err)
+	dc := gg.NewContext(1, 1)
+	dc.SetFontFace(face)
+
+	text := "Warga Jakarta heboh, banjir setinggi dua meter melanda kota tadi pagi supercalifragilisticexpialidocious"
+	style := &textStyle{face: face, fill: image.Black}
+
+	for _, width := range []float64{120, 300, 600, 2000} {
+		var got []string
+		for _, para := range tokenize([]textPiece{{text: text, style: style}}, false) {
+			for _, line := range wrapWords(para, width) {
+				got = append(got, lineText(line))
+			}
+		}
+		assert.Equal(t, dc.WordWrap(text, width), got, "width %v", width)
+	}
+}
+
+func TestWrapWords_MixedStylesShareLines(t *testing.T) {
+	face, err := loadFontFace(testFont, 40)
+	require.NoError(t, err)
+
+	plain := &textStyle{face: face, fill: image.Black}
+	bold := &textStyle{face: face, fill: image.Black, boldness: 2}
+	pieces := []textPiece{{text: "breaking ", style: plain}, {text: "news", style: bold}, {text: " today\nsecond", style: plain}}
+
+	paragraphs := tokenize(pieces, false)
+	require.Len(t, paragraphs, 2)
+
+	lines := wrapWords(paragraphs[0], 1000)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "breaking news today", lineText(lines[0]))
+	assert.Equal(t, "second", lineText(wrapWords(paragraphs[1], 1000)[0]))
+}
+
+func TestLayoutParagraphs_WrapModes(t *testing.T) {
+	face, err := loadFontFace(testFont, 40)
+	require.NoError(t, err)
+	style := &textStyle{face: face, fill: image.Black}
+	pieces := []textPiece{{text: "abcdefghij klm\r\n\nxyz", style: style}}
+
+	texts := func(lines []textLine) []string {
+		var out []string
+		for _, l := range lines {
+			out = append(out, lineText(l))
+		}
+		return out
+	}
+
+	none := layoutParagraphs(pieces, WrapNone, "", 50)
+	assert.Equal(t, []string{"abcdefghij klm", "", "xyz"}, texts(none))
+	assert.Equal(t, []bool{true, true, true}, []bool{none[0].last, none[1].last, none[2].last})
+
+	word := layoutParagraphs(pieces, WrapWord, "", 50)
+	assert.Equal(t, []string{"abcdefghij", "klm", "", "xyz"}, texts(word))
+
+	char := layoutParagraphs(pieces, WrapChar, "", 100)
+	require.Greater(t, len(char), 4)
+	for _, l := range char {
+		assert.LessOrEqual(t, l.width.Floor(), 100, lineText(l))
+	}
+	assert.Equal(t, "abcdefghij klm", strings.Join(texts(char[:len(char)-2]), ""))
+	assert.False(t, char[0].last)
+}
+
+func TestLetterSpacingAndJustify(t *testing.T) {
+	face, err := loadFontFace(testFont, 40)
+	require.NoError(t, err)
+
+	plain := &textStyle{face: face, fill: image.Black}
+	spaced := &textStyle{face: face, fill: image.Black, letterSpacing: fixed.I(3)}
+
+	base := measureTokens(tokenize([]textPiece{{text: "abcd", style: plain}}, false)[0])
+	wide := measureTokens(tokenize([]textPiece{{text: "abcd", style: spaced}}, false)[0])
+	// jarak hanya di antara huruf, bukan setelah huruf terakhir
+	assert.Equal(t, base+fixed.I(9), wide)
+
+	lines := layoutParagraphs([]textPiece{{text: "aa bb cc dd ee", style: plain}}, WrapWord, "", 150)
+	require.Greater(t, len(lines), 1)
+	assert.Zero(t, justifySpace(lines[len(lines)-1], 150))
+
+	first := lines[0]
+	gaps := strings.Count(lineText(first), " ")
+	extra := justifySpace(first, 150)
+	assert.Positive(t, extra)
+	assert.InDelta(t, 150, float64(first.width+extra*fixed.Int26_6(gaps))/64, 1)
+}
@@ -0,0 +1,124 @@
+package imageutil
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"unicode/utf8"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	WatermarkTopLeft     = "top-left"
+	WatermarkTopRight    = "top-right"
+	WatermarkBottomLeft  = "bottom-left"
+	WatermarkBottomRight = "bottom-right"
+	WatermarkCenter      = "center"
+)
+
+// Watermark gambar yang ditempel di pojok hasil akhir. Ukuran dan margin relatif terhadap
+// gambar hasil sehingga watermark terlihat sama di preset besar maupun kecil.
+type Watermark struct {
+	Image    image.Image
+	Position string  // lihat konstanta Watermark*, kosong = bottom-right
+	Margin   float64 // jarak dari tepi, pecahan dari sisi terpendek gambar
+	Scale    float64 // lebar watermark, pecahan dari lebar gambar
+	Opacity  float64 // 0..1
+}
+
+func (w *Watermark) Validate() error {
+	if w.Image == nil {
+		return errors.New("watermark has no image")
+	}
+	switch w.Position {
+	case "", WatermarkTopLeft, WatermarkTopRight, WatermarkBottomLeft, WatermarkBottomRight, WatermarkCenter:
+	default:
+		return fmt.Errorf("invalid watermark position %q", w.Position)
+	}
+	if w.Margin < 0 || w.Margin >= 0.5 {
+		return errors.New("watermark margin must be between 0 and 0.5")
+	}
+	if w.Scale <= 0 || w.Scale > 1 {
+		return errors.New("watermark scale must be between 0 and 1")
+	}
+	if w.Opacity < 0 || w.Opacity > 1 {
+		return errors.New("watermark opacity must be between 0 and 1")
+	}
+	return nil
+}
+
+// RenderTextWatermark menggambar teks satu baris menjadi gambar transparan seukuran teksnya,
+// supaya watermark teks bisa diperlakukan sama seperti watermark gambar
+func RenderTextWatermark(text, fontPath, color string, opts TextOptions) (image.Image, error) {
+	const size = 96
+	w, h := size*(utf8.RuneCountInString(text)+2), size*2
+	box := TextBox{Name: "watermark", Width: w, Height: h, Font: fontPath, Size: size, Color: color, LineSpacing: 1, Wrap: WrapNone}
+	img, err := DrawTextBoxes(NewCanvas(w, h), map[string]string{box.Name: text}, []TextBox{box}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := opaqueBounds(img)
+	if trimmed.Empty() {
+		return nil, errors.New("watermark text is empty")
+	}
+	return img.(*image.RGBA).SubImage(trimmed), nil
+}
+
+// opaqueBounds area terkecil yang berisi semua pixel tidak transparan
+func opaqueBounds(img image.Image) image.Rectangle {
+	b := img.Bounds()
+	r := image.Rectangle{Min: b.Max, Max: b.Min}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a == 0 {
+				continue
+			}
+			r.Min.X, r.Min.Y = min(r.Min.X, x), min(r.Min.Y, y)
+			r.Max.X, r.Max.Y = max(r.Max.X, x+1), max(r.Max.Y, y+1)
+		}
+	}
+	if r.Min.X >= r.Max.X {
+		return image.Rectangle{}
+	}
+	return r
+}
+
+// ApplyWatermark mengembalikan salinan img dengan watermark, img tidak diubah
+func ApplyWatermark(img image.Image, w *Watermark) *image.RGBA {
+	b := img.Bounds()
+	canvas := NewCanvas(b.Dx(), b.Dy())
+	draw.Draw(canvas, canvas.Rect, img, b.Min, draw.Src)
+
+	// lebar mengikuti scale, tinggi ikut rasio tetapi tidak boleh lebih tinggi dari gambar
+	wb := w.Image.Bounds()
+	width := w.Scale * float64(b.Dx())
+	height := width * float64(wb.Dy()) / float64(wb.Dx())
+	if height > float64(b.Dy()) {
+		width, height = width*float64(b.Dy())/height, float64(b.Dy())
+	}
+	mw, mh := int(math.Round(width)), int(math.Round(height))
+	if mw <= 0 || mh <= 0 {
+		return canvas
+	}
+	mark := image.NewRGBA(image.Rect(0, 0, mw, mh))
+	draw.CatmullRom.Scale(mark, mark.Rect, w.Image, wb, draw.Src, nil)
+
+	margin := int(math.Round(w.Margin * float64(min(b.Dx(), b.Dy()))))
+	x, y := b.Dx()-mw-margin, b.Dy()-mh-margin
+	switch w.Position {
+	case WatermarkTopLeft:
+		x, y = margin, margin
+	case WatermarkTopRight:
+		y = margin
+	case WatermarkBottomLeft:
+		x = margin
+	case WatermarkCenter:
+		x, y = (b.Dx()-mw)/2, (b.Dy()-mh)/2
+	}
+
+	DrawLayer(canvas, mark, x, y, w.Opacity)
+	return canvas
+}
@@ -0,0 +1,47 @@
+package imageutil
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyWatermark(t *testing.T) {
+	base := NewCanvas(200, 100)
+	draw.Draw(base, base.Rect, image.White, image.Point{}, draw.Src)
+	mark := NewCanvas(10, 5)
+	draw.Draw(mark, mark.Rect, image.NewUniform(color.RGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+
+	// lebar 0.25*200 = 50, tinggi 25, margin 0.1*100 = 10 dari kanan bawah
+	w := &Watermark{Image: mark, Scale: 0.25, Margin: 0.1, Opacity: 1}
+	require.NoError(t, w.Validate())
+	out := ApplyWatermark(base, w)
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, out.At(140, 65))
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, out.At(189, 89))
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, out.At(190, 90))
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, out.At(139, 64))
+	// gambar asli tidak berubah
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, base.At(150, 70))
+
+	w.Position, w.Opacity = WatermarkTopLeft, 0.5
+	r, g, _, _ := ApplyWatermark(base, w).At(10, 10).RGBA()
+	assert.Equal(t, uint32(0xffff), r)
+	assert.InDelta(t, 0x8000, g, 0x200)
+
+	assert.Error(t, (&Watermark{Image: mark, Scale: 0.2, Position: "middle"}).Validate())
+	assert.Error(t, (&Watermark{Image: mark, Scale: 0}).Validate())
+}
+
+func TestRenderTextWatermark(t *testing.T) {
+	img, err := RenderTextWatermark("MemeCraft", testFont, "#ffffff", TextOptions{})
+	require.NoError(t, err)
+
+	// gambar dipotong sampai ke teks, jadi lebar kira-kira panjang teks dan tidak ada tepi kosong
+	b := img.Bounds()
+	assert.Greater(t, b.Dx(), b.Dy()*3)
+	assert.Equal(t, b, opaqueBounds(img))
+}
@@ -0,0 +1,229 @@
+package meme
+
+import (
+	"MemeCraft/internal/preset"
+	"MemeCraft/internal/service/imageutil"
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// renderedLayer gambar layer yang sudah dirender beserta posisinya, img nil = layer dilewati
+type renderedLayer struct {
+	img  image.Image
+	x, y int
+}
+
+// textInput teks dari request beserta override style per text box
+type textInput struct {
+	values map[string]string
+	styles map[string]TextStyle  // key = nama text box, sudah divalidasi resolveStyles
+	codes  map[string]string     // isi layer code, key = ref layer
+	shapes map[string]ShapeStyle // key = nama layer shape, sudah divalidasi checkShapes
+}
+
+// boxes text box preset yang sudah diberi override dari request
+func (t textInput) boxes(sets []preset.TextBox) []imageutil.TextBox {
+	merged := make([]preset.TextBox, len(sets))
+	for i, tb := range sets {
+		if s, ok := t.styles[tb.Name]; ok {
+			tb = s.apply(tb)
+		}
+		merged[i] = tb
+	}
+	return convertTextBoxPreset(merged)
+}
+
+// renderLayers menyusun seluruh layer preset dari bawah ke atas di atas kanvas seukuran base image.
+// variants pilihan gambar layer "image" dari request, sudah divalidasi checkVariants.
+func renderLayers(p *preset.Preset, overlays map[string]image.Image, variants map[string]string, text textInput, textOpts imageutil.TextOptions) (image.Image, error) {
+	rendered, err := prerenderLayers(p, overlays, variants, text, textOpts)
+	if err != nil {
+		return nil, err
+	}
+	return composeLayers(p, rendered), nil
+}
+
+// prerenderLayers merender setiap layer sekali, hasilnya bisa dipakai ulang untuk banyak frame
+func prerenderLayers(p *preset.Preset, overlays map[string]image.Image, variants map[string]string, text textInput, textOpts imageutil.TextOptions) ([]renderedLayer, error) {
+	rendered := make([]renderedLayer, len(p.Layers))
+	for i := range p.Layers {
+		img, x, y, err := renderLayer(p, &p.Layers[i], overlays, variants, text, textOpts)
+		if err != nil {
+			return nil, err
+		}
+		rendered[i] = renderedLayer{img: img, x: x, y: y}
+	}
+	return rendered, nil
+}
+
+// composeLayers menumpuk layer yang sudah dirender dengan blend mode dan opacity masing-masing
+func composeLayers(p *preset.Preset, rendered []renderedLayer) *image.RGBA {
+	bounds := p.BaseImageDecoded.Bounds()
+	canvas := imageutil.NewCanvas(bounds.Dx(), bounds.Dy())
+	composeRange(canvas, p, rendered, 0, len(rendered))
+	return canvas
+}
+
+// composeRange menumpuk layer rendered[from:to] ke canvas
+func composeRange(canvas *image.RGBA, p *preset.Preset, rendered []renderedLayer, from, to int) {
+	for i := from; i < to; i++ {
+		r := rendered[i]
+		if r.img == nil {
+			continue
+		}
+		layer := &p.Layers[i]
+		imageutil.Composite(canvas, r.img, r.x, r.y, imageutil.BlendMode(layer.Blend), layer.Alpha())
+	}
+}
+
+// renderAnimation menyusun setiap frame overlay animasi di slot, layer lain (base, teks, shape)
+// dirender sekali saja lalu dipakai ulang di semua frame
+func renderAnimation(p *preset.Preset, overlays map[string]image.Image, variants map[string]string, slot string, frames []image.Image, text textInput, textOpts imageutil.TextOptions) ([]image.Image, error) {
+	rendered, err := prerenderLayers(p, overlays, variants, text, textOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var animated []int
+	for i, layer := range p.Layers {
+		if layer.Type == preset.LayerOverlay && layer.Ref == slot {
+			animated = append(animated, i)
+		}
+	}
+	first, last := animated[0], animated[len(animated)-1]
+
+	// layer di bawah slot animasi cukup disusun sekali
+	below := composeLayers(p, rendered[:first])
+
+	// layer di atasnya juga bisa disusun sekali ke kanvas transparan bila semuanya blend normal,
+	// karena operasi "over" bersifat asosiatif
+	var above *image.RGBA
+	if allNormalBlend(p.Layers[last+1:]) {
+		above = imageutil.NewCanvas(below.Rect.Dx(), below.Rect.Dy())
+		composeRange(above, p, rendered, last+1, len(rendered))
+	}
+
+	result := make([]image.Image, len(frames))
+	for f, frame := range frames {
+		for _, i := range animated {
+			rendered[i].img = frame
+		}
+
+		canvas := imageutil.NewCanvas(below.Rect.Dx(), below.Rect.Dy())
+		copy(canvas.Pix, below.Pix)
+		if above != nil {
+			composeRange(canvas, p, rendered, first, last+1)
+			imageutil.Composite(canvas, above, 0, 0, imageutil.BlendNormal, 1)
+		} else {
+			composeRange(canvas, p, rendered, first, len(rendered))
+		}
+		result[f] = canvas
+	}
+
+	return result, nil
+}
+
+func allNormalBlend(layers []preset.Layer) bool {
+	for _, layer := range layers {
+		if mode, _ := imageutil.ParseBlendMode(layer.Blend); mode != imageutil.BlendNormal {
+			return false
+		}
+	}
+	return true
+}
+
+// renderLayer mengembalikan gambar layer beserta posisinya di kanvas, nil bila layer tidak perlu digambar
+func renderLayer(p *preset.Preset, layer *preset.Layer, overlays map[string]image.Image, variants map[string]string, text textInput, textOpts imageutil.TextOptions) (image.Image, int, int, error) {
+	bounds := p.BaseImageDecoded.Bounds()
+
+	switch layer.Type {
+	case preset.LayerBase:
+		return p.BaseImageDecoded, 0, 0, nil
+	case preset.LayerOverlay:
+		img, ok := overlays[layer.Ref]
+		if !ok {
+			return nil, 0, 0, nil
+		}
+		slot, _ := p.OverlaySlot(layer.Ref)
+		return img, slot.X, slot.Y, nil
+	case preset.LayerImage:
+		if v, ok := variants[layer.Variant]; ok && layer.Variant != "" {
+			return layer.VariantsDecoded[v], layer.X, layer.Y, nil
+		}
+		// layer variant tanpa gambar default hanya digambar bila dipilih
+		if layer.ImageDecoded == nil {
+			return nil, 0, 0, nil
+		}
+		return layer.ImageDecoded, layer.X, layer.Y, nil
+	case preset.LayerText:
+		boxes := p.TextBoxes
+		if layer.Ref != "" {
+			tb, _ := p.TextBox(layer.Ref)
+			boxes = []preset.TextBox{*tb}
+		}
+		img, err := imageutil.DrawTextBoxes(imageutil.NewCanvas(bounds.Dx(), bounds.Dy()), text.values, text.boxes(boxes), textOpts)
+		if err != nil {
+			log.Errorf("Error while drawing text: %v", err)
+			return nil, 0, 0, errors.New("failed to draw text")
+		}
+		return img, 0, 0, nil
+	case preset.LayerCode:
+		content, ok := text.codes[layer.Ref]
+		if !ok {
+			return nil, 0, 0, nil
+		}
+		img, err := imageutil.DrawCode(imageutil.Code{
+			Format:          layer.Code.Format,
+			Content:         content,
+			Width:           layer.Width,
+			Height:          layer.Height,
+			Foreground:      layer.Code.Foreground,
+			Background:      layer.Code.Background,
+			ErrorCorrection: layer.Code.ErrorCorrection,
+		})
+		if err != nil {
+			// isi dari request yang tidak bisa di-encode (mis. EAN bukan angka) dilaporkan ke client
+			return nil, 0, 0, fmt.Errorf("code %s: %w", layer.Ref, err)
+		}
+		return img, layer.X, layer.Y, nil
+	case preset.LayerShape:
+		img, err := imageutil.DrawShape(bounds.Dx(), bounds.Dy(), text.shape(layer))
+		if err != nil {
+			log.Errorf("Error while drawing shape: %v", err)
+			return nil, 0, 0, errors.New("failed to draw shape")
+		}
+		return img, 0, 0, nil
+	}
+
+	return nil, 0, 0, nil
+}
+
+// shape layer shape preset yang sudah diberi override warna dari request
+func (t textInput) shape(layer *preset.Layer) imageutil.Shape {
+	s := convertShapePreset(layer)
+	if style, ok := t.shapes[layer.Name]; ok && layer.Shape.Overridable != nil {
+		s = style.apply(s)
+	}
+	return s
+}
+
+func convertShapePreset(layer *preset.Layer) imageutil.Shape {
+	s := layer.Shape
+	return imageutil.Shape{
+		Kind:        s.Kind,
+		X:           float64(layer.X),
+		Y:           float64(layer.Y),
+		Width:       float64(layer.Width),
+		Height:      float64(layer.Height),
+		Color:       s.Color,
+		Radius:      s.Radius,
+		From:        s.From,
+		To:          s.To,
+		Angle:       s.Angle,
+		Stroke:      s.Stroke,
+		StrokeWidth: s.StrokeWidth,
+	}
+}
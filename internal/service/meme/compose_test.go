@@ -0,0 +1,68 @@
+package meme
+
+import (
+	"MemeCraft/internal/preset"
+	"MemeCraft/internal/service/imageutil"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func solid(c color.Color, w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Rect, image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+func TestRenderLayers_Variants(t *testing.T) {
+	red, blue := color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}
+	p := &preset.Preset{
+		BaseImageDecoded: solid(color.White, 20, 20),
+		Layers: []preset.Layer{
+			{Type: preset.LayerBase},
+			{Type: preset.LayerImage, X: 5, Y: 5, Variant: "brand",
+				Variants:        map[string]string{"kompas": "kompas.png", "detik": "detik.png"},
+				VariantsDecoded: map[string]image.Image{"kompas": solid(red, 4, 4), "detik": solid(blue, 4, 4)}},
+			{Type: preset.LayerImage, Variant: "badge",
+				Variants:        map[string]string{"live": "live.png"},
+				VariantsDecoded: map[string]image.Image{"live": solid(red, 2, 2)}},
+		},
+	}
+	assert.Equal(t, map[string][]string{"brand": {"detik", "kompas"}, "badge": {"live"}}, p.VariantOptions())
+
+	img, err := renderLayers(p, nil, map[string]string{"brand": "detik"}, textInput{}, imageutil.TextOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{B: 255, A: 255}, img.At(6, 6))
+	// badge tanpa gambar default tidak digambar bila tidak dipilih
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, img.At(0, 0))
+
+	assert.NoError(t, checkVariants(p, map[string]string{"brand": "kompas", "badge": "live"}))
+	assert.EqualError(t, checkVariants(p, map[string]string{"brand": "tempo"}), `variant brand: unknown value "tempo"`)
+	assert.EqualError(t, checkVariants(p, map[string]string{"logo": "kompas"}), "unknown variant: logo")
+}
+
+func TestResolveCodes(t *testing.T) {
+	tmpl, err := preset.ParseTemplate("https://example.com/{{lower .headline}}")
+	require.NoError(t, err)
+	p := &preset.Preset{Layers: []preset.Layer{
+		{Type: preset.LayerCode, Ref: "article", Code: &preset.Code{Default: "x", DefaultTemplate: tmpl}},
+		{Type: preset.LayerCode, Ref: "promo", Code: &preset.Code{}},
+	}}
+	now := time.Now()
+
+	codes, err := resolveCodes(p, nil, map[string]string{"headline": "BANJIR"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"article": "https://example.com/banjir"}, codes)
+
+	codes, err = resolveCodes(p, map[string]string{"article": "https://a.b/c", "promo": "PROMO10"}, nil, now)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"article": "https://a.b/c", "promo": "PROMO10"}, codes)
+
+	_, err = resolveCodes(p, map[string]string{"coupon": "x"}, nil, now)
+	assert.EqualError(t, err, "unknown code: coupon")
+}
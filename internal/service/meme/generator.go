@@ -5,11 +5,14 @@ import (
 	"MemeCraft/internal/port"
 	"MemeCraft/internal/preset"
 	"MemeCraft/internal/service/imageutil"
+	"MemeCraft/internal/service/provenance"
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"image"
 	"image/jpeg"
+	"slices"
 	"time"
 
 	"github.com/gofiber/fiber/v2/log"
@@ -18,74 +21,325 @@ import (
 type Generator struct {
 	registry       *preset.Registry
 	storageAdapter port.StorageProvider
+	textOptions    imageutil.TextOptions
+	watermark      *WatermarkPolicy    // nil = tanpa watermark
+	provenance     *provenance.Service // nil = hasil tidak ditandai dan /verify tidak aktif
 }
 
+// ErrProvenanceDisabled dikembalikan Verify bila server tidak punya provenance key
+var ErrProvenanceDisabled = errors.New("provenance is not configured on this server")
+
 func (g *Generator) Generate(config *Config) (*domain.Meme, error) {
 	p, ok := g.registry.Get(config.PresetId)
 	if !ok {
 		return nil, errors.New("preset not found")
 	}
 
-	if len(config.Overlay) == 0 {
-		return nil, errors.New("no overlays specified")
+	styles, err := resolveStyles(p, config)
+	if err != nil {
+		return nil, err
 	}
 
-	overlay, _, err := image.Decode(bytes.NewReader(config.Overlay))
-	if err != nil {
-		log.Errorf("Error decoding overlay: %v", err)
-		log.Infof("overlay length: %d", len(config.Overlay))
+	if err := checkVariants(p, config.Variants); err != nil {
 		return nil, err
 	}
 
-	resizeMode := p.ResizeMode
-	if config.ResizeMode != "" {
-		resizeMode = imageutil.ResizeMode(config.ResizeMode)
+	if err := checkShapes(p, config.Shapes); err != nil {
+		return nil, err
 	}
-	overlay, err = imageutil.ResizeWithMode(overlay, resizeMode, p.Overlay.Width, p.Overlay.Height)
+
+	overlays, err := resolveOverlays(p, config)
 	if err != nil {
-		log.Errorf("Error resizing overlay: %v", err)
-		return nil, errors.New("failed to resize overlay")
+		return nil, err
 	}
 
-	overlayedImage := imageutil.Overlay(p.BaseImageDecoded, overlay, p.Overlay.X, p.Overlay.Y, "back", p.Overlay.Rotate)
-
-	textbox := convertTextBoxPreset(p.TextBoxes)
-	drawnTextImage := overlayedImage
-	for k, v := range config.Text {
-		for _, tb := range textbox {
-			if tb.Name == k {
-				var box []imageutil.TextBox
-				box = append(box, tb)
-				text := map[string]string{k: v}
-				drawnTextImage, err = imageutil.DrawTextBoxes(drawnTextImage, text, box)
-				if err != nil {
-					log.Errorf("Error while drawing text: %v", err)
-					return nil, errors.New("failed to draw text")
-				}
+	prepared := make(map[string]*imageutil.Animation, len(overlays))
+	overlayImages := make(map[string]image.Image, len(overlays))
+	animatedSlot := ""
+	for name, data := range overlays {
+		slot, _ := p.OverlaySlot(name)
+		anim, err := prepareOverlay(slot, data, slotOptions(p, slot, config))
+		if err != nil {
+			return nil, err
+		}
+		if len(anim.Frames) > 1 {
+			if animatedSlot != "" {
+				return nil, errors.New("only one animated overlay is supported")
 			}
+			animatedSlot = name
 		}
+		prepared[name] = anim
+		overlayImages[name] = anim.Frames[0]
 	}
 
-	var buf bytes.Buffer
-	if err := jpeg.Encode(&buf, drawnTextImage, nil); err != nil {
-		log.Errorf("failed to encode image: %v", err)
+	now := time.Now()
+	codes, err := resolveCodes(p, config.Codes, config.Text, now)
+	if err != nil {
 		return nil, err
 	}
+	text := textInput{values: resolveText(p, config.Text, now), styles: styles, codes: codes, shapes: config.Shapes}
+	watermark := g.watermark.For(p, config.APIKey)
+	record := provenance.NewRecord(p.ID, now)
+	var buf bytes.Buffer
+	if animatedSlot == "" {
+		result, err := renderLayers(p, overlayImages, config.Variants, text, g.textOptions)
+		if err != nil {
+			return nil, err
+		}
+		// watermark selalu langkah terakhir supaya tidak tertutup layer lain
+		if watermark != nil {
+			result = imageutil.ApplyWatermark(result, watermark)
+		}
+		if g.provenance != nil {
+			result = g.provenance.Mark(result, record)
+		}
+		if err := jpeg.Encode(&buf, result, nil); err != nil {
+			log.Errorf("failed to encode image: %v", err)
+			return nil, err
+		}
+	} else {
+		anim := prepared[animatedSlot]
+		frames, err := renderAnimation(p, overlayImages, config.Variants, animatedSlot, anim.Frames, text, g.textOptions)
+		if err != nil {
+			return nil, err
+		}
+		if watermark != nil {
+			for i, frame := range frames {
+				frames[i] = imageutil.ApplyWatermark(frame, watermark)
+			}
+		}
+		data, err := imageutil.EncodeAnimation(frames, anim.Delays, anim.LoopCount)
+		if err != nil {
+			log.Errorf("failed to encode animation: %v", err)
+			return nil, err
+		}
+		buf.Write(data)
+	}
+
+	// GIF hanya diberi metadata, watermark tak terlihat hilang saat frame dikuantisasi ke 256 warna
+	data := buf.Bytes()
+	if g.provenance != nil {
+		sign := g.provenance.SignJPEG
+		if animatedSlot != "" {
+			sign = g.provenance.SignGIF
+		}
+		if data, err = sign(data, record); err != nil {
+			log.Errorf("failed to sign image: %v", err)
+			return nil, errors.New("failed to sign image")
+		}
+		log.Infof("render %s => preset %s", record.RenderID, p.ID)
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	uploadResult, err := g.storageAdapter.UploadBytes(ctx, buf.Bytes())
+	uploadResult, err := g.storageAdapter.UploadBytes(ctx, data)
 	if err != nil {
 		log.Errorf("failed to upload image: %v", err)
 		return nil, errors.New("failed to upload image")
 	}
 
-	return &domain.Meme{
+	result := &domain.Meme{
 		ImageUrl:    uploadResult.DirectURL,
 		ContentType: uploadResult.ContentType,
 		Size:        uploadResult.BytesReadable,
-	}, nil
+	}
+	if g.provenance != nil {
+		result.RenderID = record.RenderID
+	}
+	return result, nil
+}
+
+// Verify memeriksa apakah gambar dibuat oleh instance ini
+func (g *Generator) Verify(data []byte, limits imageutil.DecodeLimits) (*provenance.Result, error) {
+	if g.provenance == nil {
+		return nil, ErrProvenanceDisabled
+	}
+	return g.provenance.Verify(data, g.registry.IDs(), limits)
+}
+
+// checkVariants memastikan setiap pilihan variant dari request ada di preset
+func checkVariants(p *preset.Preset, variants map[string]string) error {
+	options := p.VariantOptions()
+	for name, value := range variants {
+		values, ok := options[name]
+		if !ok {
+			return fmt.Errorf("unknown variant: %s", name)
+		}
+		if !slices.Contains(values, value) {
+			return fmt.Errorf("variant %s: unknown value %q", name, value)
+		}
+	}
+	return nil
+}
+
+// resolveText mengisi text box yang punya default bila request tidak mengirim teksnya.
+// Placeholder di default memakai teks asli dari request dan waktu request di timezone preset.
+func resolveText(p *preset.Preset, values map[string]string, now time.Time) map[string]string {
+	if p.Location != nil {
+		now = now.In(p.Location)
+	}
+	text := make(map[string]string, len(p.TextBoxes))
+	for name, v := range values {
+		text[name] = v
+	}
+	for _, tb := range p.TextBoxes {
+		if tb.DefaultTemplate == nil || values[tb.Name] != "" {
+			continue
+		}
+		text[tb.Name] = tb.DefaultTemplate.Execute(values, now, p.Locale)
+	}
+	return text
+}
+
+// resolveCodes isi layer code dari request, layer yang tidak dikirim memakai default-nya.
+// Placeholder di default diisi dengan teks request seperti default text box.
+func resolveCodes(p *preset.Preset, values, text map[string]string, now time.Time) (map[string]string, error) {
+	codes := make(map[string]string, len(values))
+	for name, v := range values {
+		if _, ok := p.CodeLayer(name); !ok {
+			return nil, fmt.Errorf("unknown code: %s", name)
+		}
+		if v != "" {
+			codes[name] = v
+		}
+	}
+
+	if p.Location != nil {
+		now = now.In(p.Location)
+	}
+	for _, l := range p.Layers {
+		if l.Type != preset.LayerCode || l.Code.DefaultTemplate == nil || codes[l.Ref] != "" {
+			continue
+		}
+		if v := l.Code.DefaultTemplate.Execute(text, now, p.Locale); v != "" {
+			codes[l.Ref] = v
+		}
+	}
+	return codes, nil
+}
+
+// resolveOverlays memetakan data overlay dari request ke nama slot preset
+func resolveOverlays(p *preset.Preset, config *Config) (map[string][]byte, error) {
+	overlays := make(map[string][]byte, len(config.Overlays)+1)
+	for _, names := range [][]string{mapKeys(config.Filters), mapKeys(config.Focus), mapKeys(config.Crop), mapKeys(config.Background)} {
+		for _, name := range names {
+			if _, ok := p.OverlaySlot(name); !ok {
+				return nil, fmt.Errorf("unknown overlay slot: %s", name)
+			}
+		}
+	}
+
+	for name, data := range config.Overlays {
+		if _, ok := p.OverlaySlot(name); !ok {
+			return nil, fmt.Errorf("unknown overlay slot: %s", name)
+		}
+		if len(data) > 0 {
+			overlays[name] = data
+		}
+	}
+
+	if len(config.Overlay) > 0 {
+		first := p.Overlays[0].Name
+		if _, ok := overlays[first]; ok {
+			return nil, fmt.Errorf("overlay slot %s specified twice", first)
+		}
+		overlays[first] = config.Overlay
+	}
+
+	if len(overlays) == 0 {
+		return nil, errors.New("no overlays specified")
+	}
+
+	return overlays, nil
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// overlayOptions pengaturan pemrosesan gambar user untuk satu slot
+type overlayOptions struct {
+	Limits          imageutil.DecodeLimits
+	AnimationLimits imageutil.AnimationLimits
+	Mode            imageutil.ResizeMode
+	Resize          imageutil.ResizeOptions
+	Filters         []imageutil.Filter
+	Canvas          image.Point // ukuran base image, setiap frame animasi dirender ke kanvas seukuran ini
+}
+
+// slotOptions menggabungkan pengaturan preset, slot dan request (request paling prioritas)
+func slotOptions(p *preset.Preset, slot *preset.Overlay, config *Config) overlayOptions {
+	opts := overlayOptions{
+		Limits:          config.DecodeLimits,
+		AnimationLimits: config.AnimationLimits,
+		Mode:            p.ResizeMode,
+		Resize:          imageutil.ResizeOptions{Focus: slot.Focus, Background: slot.Background},
+		Filters:         slot.Filters,
+		Canvas:          p.BaseImageDecoded.Bounds().Size(),
+	}
+
+	if slot.ResizeMode != "" {
+		opts.Mode = slot.ResizeMode
+	}
+	if config.ResizeMode != "" {
+		opts.Mode = imageutil.ResizeMode(config.ResizeMode)
+	}
+	if f, ok := config.Filters[slot.Name]; ok {
+		opts.Filters = f
+	}
+	if focus, ok := config.Focus[slot.Name]; ok {
+		opts.Resize.Focus = &focus
+	}
+	if crop, ok := config.Crop[slot.Name]; ok {
+		opts.Resize.Crop = &crop
+	}
+	if bg, ok := config.Background[slot.Name]; ok {
+		opts.Resize.Background = &bg
+	}
+
+	return opts
+}
+
+// prepareOverlay decode, resize, filter dan rotasi gambar user untuk slot overlay.
+// GIF animasi menghasilkan lebih dari satu frame, gambar biasa satu frame.
+func prepareOverlay(slot *preset.Overlay, data []byte, opts overlayOptions) (*imageutil.Animation, error) {
+	anim, err := imageutil.DecodeAnimation(data, opts.Limits, opts.AnimationLimits)
+	if err != nil {
+		log.Errorf("Error decoding overlay %s: %v", slot.Name, err)
+		log.Infof("overlay length: %d", len(data))
+		return nil, err
+	}
+	// GIF kecil di preset besar tetap menghasilkan frame seukuran kanvas, dicek sebelum resize dan render
+	if len(anim.Frames) > 1 {
+		if err := opts.AnimationLimits.CheckFrames(len(anim.Frames), opts.Canvas.X, opts.Canvas.Y); err != nil {
+			return nil, err
+		}
+	}
+
+	frames, err := imageutil.ResizeFrames(anim.Frames, opts.Mode, slot.Width, slot.Height, opts.Resize)
+	if err != nil {
+		log.Errorf("Error resizing overlay %s: %v", slot.Name, err)
+		return nil, errors.New("failed to resize overlay")
+	}
+
+	for i, frame := range frames {
+		frame, err = imageutil.ApplyFilters(frame, opts.Filters)
+		if err != nil {
+			return nil, fmt.Errorf("overlay %s: %w", slot.Name, err)
+		}
+		if slot.MaskAlpha != nil {
+			frame = imageutil.ApplyMask(frame, slot.MaskAlpha)
+		}
+		frames[i] = imageutil.Rotate(frame, slot.Rotate)
+	}
+
+	anim.Frames = frames
+	return anim, nil
 }
 
 func convertTextBoxPreset(sets []preset.TextBox) []imageutil.TextBox {
@@ -99,13 +353,27 @@ func convertTextBoxPreset(sets []preset.TextBox) []imageutil.TextBox {
 			Width:       p.Width,
 			Height:      p.Height,
 			Font:        p.Font,
+			BoldFont:    p.BoldFont,
 			Size:        p.Size,
 			LineSpacing: p.LineSpacing,
 			Color:       p.Color,
 			Align:       p.Align,
+			Wrap:        p.Wrap,
 			Padding:     p.Padding,
 			MaxChars:    p.MaxChars,
 			Normalize:   p.Normalize,
+			Direction:   p.Direction,
+
+			LetterSpacing: p.LetterSpacing,
+			FallbackFonts: p.FallbackFonts,
+
+			Rotate: p.Rotate,
+			SkewX:  p.SkewX,
+
+			BackgroundColor:   p.BackgroundColor,
+			BackgroundPadding: p.BackgroundPadding,
+			BackgroundRadius:  p.BackgroundRadius,
+			BackgroundMode:    p.BackgroundMode,
 		}
 	}
 
@@ -124,10 +392,13 @@ func (g *Generator) GetAllPreset() []*preset.PresetSummary {
 	return g.registry.GetAll()
 }
 
-func NewGenerator(reg *preset.Registry, storageAdapter port.StorageProvider) *Generator {
+func NewGenerator(reg *preset.Registry, storageAdapter port.StorageProvider, textOptions imageutil.TextOptions, watermark *WatermarkPolicy, provenance *provenance.Service) *Generator {
 	log.Infof("Storage adapter => %s", storageAdapter.GetStorageName())
 	return &Generator{
 		registry:       reg,
 		storageAdapter: storageAdapter,
+		textOptions:    textOptions,
+		watermark:      watermark,
+		provenance:     provenance,
 	}
 }
@@ -0,0 +1,70 @@
+package meme
+
+import (
+	"MemeCraft/internal/preset"
+	"MemeCraft/internal/service/imageutil"
+	"bytes"
+	"image"
+	"image/color/palette"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveOverlays(t *testing.T) {
+	p := &preset.Preset{Overlays: []preset.Overlay{{Name: "before"}, {Name: "after"}}}
+
+	// overlay lama selalu masuk ke slot pertama
+	overlays, err := resolveOverlays(p, &Config{Overlay: []byte("a"), Overlays: map[string][]byte{"after": []byte("b")}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"before": []byte("a"), "after": []byte("b")}, overlays)
+
+	for _, config := range []*Config{
+		{},
+		{Overlays: map[string][]byte{"before": nil}},
+		{Overlays: map[string][]byte{"missing": []byte("a")}},
+		{Overlay: []byte("a"), Overlays: map[string][]byte{"before": []byte("b")}},
+		{Overlay: []byte("a"), Crop: map[string]imageutil.CropRect{"missing": {}}},
+	} {
+		_, err := resolveOverlays(p, config)
+		assert.Error(t, err, "%+v", config)
+	}
+}
+
+func TestPrepareOverlay_LimitErrors(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 40, 30))))
+	slot := &preset.Overlay{Name: "photo", Width: 10, Height: 10}
+
+	// handler memetakan sentinel ini ke 413 dan 422
+	_, err := prepareOverlay(slot, buf.Bytes(), overlayOptions{Limits: imageutil.DecodeLimits{MaxPixels: 1000}})
+	assert.ErrorIs(t, err, imageutil.ErrImageTooLarge)
+
+	_, err = prepareOverlay(slot, []byte("not an image"), overlayOptions{})
+	assert.ErrorIs(t, err, imageutil.ErrInvalidImage)
+
+	_, err = prepareOverlay(slot, buf.Bytes(), overlayOptions{Mode: imageutil.Stretch})
+	assert.NoError(t, err)
+}
+
+func TestPrepareOverlay_AnimationCanvas(t *testing.T) {
+	// GIF 1x1 dengan 100 frame hanya 100 pixel, tetapi setiap frame hasil seukuran kanvas preset
+	g := &gif.GIF{}
+	for range 100 {
+		g.Image = append(g.Image, image.NewPaletted(image.Rect(0, 0, 1, 1), palette.Plan9))
+		g.Delay = append(g.Delay, 10)
+	}
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, g))
+	slot := &preset.Overlay{Name: "photo", Width: 10, Height: 10}
+
+	_, err := prepareOverlay(slot, buf.Bytes(), overlayOptions{Mode: imageutil.Stretch, Canvas: image.Pt(2000, 1500)})
+	assert.ErrorIs(t, err, imageutil.ErrAnimationTooLong)
+
+	anim, err := prepareOverlay(slot, buf.Bytes(), overlayOptions{Mode: imageutil.Stretch, Canvas: image.Pt(200, 150)})
+	require.NoError(t, err)
+	assert.Len(t, anim.Frames, 100)
+}
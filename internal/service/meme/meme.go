@@ -1,8 +1,24 @@
 package meme
 
+import "MemeCraft/internal/service/imageutil"
+
 type Config struct {
 	PresetId   string
-	Overlay    []byte
+	Overlay    []byte            // dipakai untuk slot pertama (kompatibilitas request lama)
+	Overlays   map[string][]byte // key = nama slot overlay
 	ResizeMode string
+	Filters    map[string][]imageutil.Filter   // key = nama slot, menggantikan filter preset
+	Focus      map[string]imageutil.FocalPoint // key = nama slot, titik fokus crop fill/smart
+	Crop       map[string]imageutil.CropRect   // key = nama slot, crop manual sebelum resize
+	Background map[string]imageutil.Background // key = nama slot, background mode contain
 	Text       map[string]string
+	Colors     map[string]string     // key = nama text box, bentuk singkat Styles[box].Color
+	Styles     map[string]TextStyle  // key = nama text box, dibatasi overridable preset
+	Variants   map[string]string     // key = nama variant layer image, value = pilihan
+	APIKey     string                // untuk aturan watermark per API key, kosong = watermark default
+	Codes      map[string]string     // key = ref layer code, isi QR code/barcode
+	Shapes     map[string]ShapeStyle // key = nama layer shape, dibatasi overridable preset
+
+	DecodeLimits    imageutil.DecodeLimits    // batas dimensi overlay, kosong = imageutil.DefaultDecodeLimits
+	AnimationLimits imageutil.AnimationLimits // batas frame/durasi GIF overlay, kosong = imageutil.DefaultAnimationLimits
 }
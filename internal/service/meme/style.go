@@ -0,0 +1,158 @@
+package meme
+
+import (
+	"MemeCraft/internal/preset"
+	"MemeCraft/internal/service/imageutil"
+	"fmt"
+	"image/color"
+	"slices"
+)
+
+// TextStyle override style satu text box dari request, field kosong = ikut preset
+type TextStyle struct {
+	Color     string
+	Size      float64
+	Align     string
+	Normalize string
+}
+
+// ShapeStyle override warna layer shape dari request, field kosong = ikut preset
+type ShapeStyle struct {
+	Color  string
+	Stroke string
+}
+
+// resolveStyles mengubah Colors dari request menjadi style.color (satu-satunya jalur override
+// warna) lalu memeriksa setiap override terhadap batas overridable text box. Warna yang diisi di
+// Colors dan Styles sekaligus untuk box yang sama ditolak supaya tidak ada urutan prioritas.
+func resolveStyles(p *preset.Preset, config *Config) (map[string]TextStyle, error) {
+	styles := make(map[string]TextStyle, len(config.Colors)+len(config.Styles))
+	for name, s := range config.Styles {
+		styles[name] = s
+	}
+	for name, c := range config.Colors {
+		s := styles[name]
+		if s.Color != "" {
+			return nil, fmt.Errorf("text box %s: color is set in both colors and style", name)
+		}
+		s.Color = c
+		styles[name] = s
+	}
+
+	for name, s := range styles {
+		tb, ok := p.TextBox(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown text box: %s", name)
+		}
+		if err := checkStyle(tb.Overridable, s); err != nil {
+			return nil, fmt.Errorf("text box %s: %w", name, err)
+		}
+	}
+	return styles, nil
+}
+
+func checkStyle(limits *preset.StyleLimits, s TextStyle) error {
+	if limits == nil {
+		limits = &preset.StyleLimits{Color: true}
+	}
+
+	if s.Color != "" {
+		if !limits.Color && len(limits.Palette) == 0 {
+			return fmt.Errorf("color can't be changed")
+		}
+		// hanya warna solid, gradient dan pattern tetap diatur oleh preset
+		c, err := imageutil.ParseColor(s.Color)
+		if err != nil {
+			return err
+		}
+		if len(limits.Palette) > 0 && !inPalette(limits.Palette, c) {
+			return fmt.Errorf("color %s is not in the allowed palette", s.Color)
+		}
+	}
+	if s.Size != 0 {
+		if limits.MaxSize == 0 {
+			return fmt.Errorf("size can't be changed")
+		}
+		if s.Size < limits.MinSize || s.Size > limits.MaxSize {
+			return fmt.Errorf("size must be between %v and %v", limits.MinSize, limits.MaxSize)
+		}
+	}
+	if s.Align != "" && !slices.Contains(limits.Align, s.Align) {
+		return fmt.Errorf("align %q is not allowed", s.Align)
+	}
+	if s.Normalize != "" && !slices.Contains(limits.Normalize, s.Normalize) {
+		return fmt.Errorf("normalize %q is not allowed", s.Normalize)
+	}
+	return nil
+}
+
+// checkShapes memeriksa override warna shape terhadap overridable setiap layer dengan nama itu.
+// Shape tanpa overridable tidak bisa diganti, berbeda dengan text box.
+func checkShapes(p *preset.Preset, shapes map[string]ShapeStyle) error {
+	for name, s := range shapes {
+		found := false
+		for _, l := range p.Layers {
+			if l.Type != preset.LayerShape || l.Name != name {
+				continue
+			}
+			found = true
+			if l.Shape.Overridable == nil {
+				return fmt.Errorf("shape %s: color can't be changed", name)
+			}
+			for _, c := range []string{s.Color, s.Stroke} {
+				if err := checkStyle(l.Shape.Overridable, TextStyle{Color: c}); err != nil {
+					return fmt.Errorf("shape %s: %w", name, err)
+				}
+			}
+			// line tidak punya isi, warnanya dari stroke
+			if l.Shape.Kind == imageutil.ShapeLine && s.Color != "" {
+				return fmt.Errorf("shape %s: line has no fill color, use stroke", name)
+			}
+			if l.Shape.Stroke == "" && s.Stroke != "" {
+				return fmt.Errorf("shape %s: shape has no stroke", name)
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown shape: %s", name)
+		}
+	}
+	return nil
+}
+
+// apply mengganti warna shape dengan override yang diisi
+func (s ShapeStyle) apply(shape imageutil.Shape) imageutil.Shape {
+	if s.Color != "" {
+		shape.Color = s.Color
+	}
+	if s.Stroke != "" {
+		shape.Stroke = s.Stroke
+	}
+	return shape
+}
+
+// inPalette membandingkan hasil parse, jadi "red" dan "#f00" dianggap sama
+func inPalette(palette []string, c color.Color) bool {
+	for _, p := range palette {
+		if pc, err := imageutil.ParseColor(p); err == nil && pc == c {
+			return true
+		}
+	}
+	return false
+}
+
+// apply mengganti properti text box dengan override yang diisi
+func (s TextStyle) apply(tb preset.TextBox) preset.TextBox {
+	if s.Color != "" {
+		tb.Color = s.Color
+	}
+	if s.Size != 0 {
+		tb.Size = s.Size
+	}
+	if s.Align != "" {
+		tb.Align = s.Align
+	}
+	if s.Normalize != "" {
+		tb.Normalize = s.Normalize
+	}
+	return tb
+}
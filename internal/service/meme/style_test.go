@@ -0,0 +1,97 @@
+package meme
+
+import (
+	"MemeCraft/internal/preset"
+	"MemeCraft/internal/service/imageutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveStyles(t *testing.T) {
+	p := &preset.Preset{TextBoxes: []preset.TextBox{
+		{Name: "headline", Color: "#000000", Size: 40, Overridable: &preset.StyleLimits{
+			Palette: []string{"red", "#ffffff"}, MinSize: 30, MaxSize: 60, Align: []string{"left", "center"},
+		}},
+		{Name: "caption"}, // tanpa overridable hanya warna
+	}}
+
+	styles, err := resolveStyles(p, &Config{
+		Colors: map[string]string{"caption": "rgb(0, 128, 0)"},
+		Styles: map[string]TextStyle{"headline": {Color: "#f00", Size: 50, Align: "center"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, TextStyle{Color: "#f00", Size: 50, Align: "center"}, styles["headline"])
+	assert.Equal(t, TextStyle{Color: "rgb(0, 128, 0)"}, styles["caption"])
+
+	boxes := textInput{styles: styles}.boxes(p.TextBoxes)
+	assert.Equal(t, "#f00", boxes[0].Color)
+	assert.Equal(t, 50.0, boxes[0].Size)
+	assert.Equal(t, "center", boxes[0].Align)
+	assert.Equal(t, "#000000", p.TextBoxes[0].Color, "preset tidak berubah")
+
+	for _, config := range []*Config{
+		{Styles: map[string]TextStyle{"missing": {Color: "#fff"}}},
+		{Styles: map[string]TextStyle{"headline": {Color: "#0000ff"}}},
+		{Styles: map[string]TextStyle{"headline": {Size: 80}}},
+		{Styles: map[string]TextStyle{"headline": {Align: "right"}}},
+		{Styles: map[string]TextStyle{"headline": {Normalize: "toupper"}}},
+		{Styles: map[string]TextStyle{"caption": {Size: 30}}},
+		{Colors: map[string]string{"caption": "linear-gradient(#fff, #000)"}},
+		{Colors: map[string]string{"headline": "#0000ff"}}, // colors diperiksa dengan palette yang sama
+	} {
+		_, err := resolveStyles(p, config)
+		assert.Error(t, err, "%+v", config)
+	}
+}
+
+func TestResolveStyles_ColorsIsStyleColor(t *testing.T) {
+	p := &preset.Preset{TextBoxes: []preset.TextBox{
+		{Name: "headline", Overridable: &preset.StyleLimits{Color: true, MinSize: 30, MaxSize: 60}},
+	}}
+
+	// colors hanya bentuk singkat style.color, field style lain tetap dipakai
+	styles, err := resolveStyles(p, &Config{
+		Colors: map[string]string{"headline": "#ffffff"},
+		Styles: map[string]TextStyle{"headline": {Size: 40}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, TextStyle{Color: "#ffffff", Size: 40}, styles["headline"])
+
+	// warna di kedua tempat tidak punya prioritas, request ditolak
+	_, err = resolveStyles(p, &Config{
+		Colors: map[string]string{"headline": "#ffffff"},
+		Styles: map[string]TextStyle{"headline": {Color: "#000000"}},
+	})
+	assert.ErrorContains(t, err, "both colors and style")
+}
+
+func TestCheckShapes(t *testing.T) {
+	limits := &preset.StyleLimits{Palette: []string{"#ff0000", "#ffffff"}}
+	p := &preset.Preset{Layers: []preset.Layer{
+		{Name: "band", Type: preset.LayerShape, Width: 100, Height: 20, Shape: &preset.Shape{Kind: imageutil.ShapeRect, Color: "#000000", Stroke: "#000000", Overridable: limits}},
+		{Name: "divider", Type: preset.LayerShape, Width: 100, Shape: &preset.Shape{Kind: imageutil.ShapeLine, Stroke: "#000000", Overridable: &preset.StyleLimits{Color: true}}},
+		{Name: "fixed", Type: preset.LayerShape, Width: 10, Height: 10, Shape: &preset.Shape{Kind: imageutil.ShapeRect, Color: "#000000"}},
+	}}
+
+	shapes := map[string]ShapeStyle{"band": {Color: "red", Stroke: "#fff"}, "divider": {Stroke: "rgb(0, 0, 255)"}}
+	require.NoError(t, checkShapes(p, shapes))
+
+	text := textInput{shapes: shapes}
+	band := text.shape(&p.Layers[0])
+	assert.Equal(t, "red", band.Color)
+	assert.Equal(t, "#fff", band.Stroke)
+	assert.Equal(t, "rgb(0, 0, 255)", text.shape(&p.Layers[1]).Stroke)
+	assert.Equal(t, "#000000", p.Layers[0].Shape.Color, "preset tidak berubah")
+
+	for _, s := range []map[string]ShapeStyle{
+		{"missing": {Color: "#fff"}},
+		{"fixed": {Color: "#fff"}},
+		{"band": {Color: "#0000ff"}},
+		{"band": {Color: "linear-gradient(#f00, #fff)"}},
+		{"divider": {Color: "#fff"}},
+	} {
+		assert.Error(t, checkShapes(p, s), "%+v", s)
+	}
+}
@@ -0,0 +1,164 @@
+package meme
+
+import (
+	"MemeCraft/internal/preset"
+	"MemeCraft/internal/service/imageutil"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// WatermarkConfig satu aturan watermark di file policy, isi image atau text
+type WatermarkConfig struct {
+	Disabled bool     `json:"disabled,omitempty"` // hanya untuk aturan API key
+	Image    string   `json:"image,omitempty"`
+	Text     string   `json:"text,omitempty"`
+	Font     string   `json:"font,omitempty"`  // wajib untuk text
+	Color    string   `json:"color,omitempty"` // warna atau gradient text, default putih
+	Position string   `json:"position,omitempty"`
+	Margin   *float64 `json:"margin,omitempty"`  // default 0.02
+	Scale    *float64 `json:"scale,omitempty"`   // default 0.2
+	Opacity  *float64 `json:"opacity,omitempty"` // default 0.6
+}
+
+// WatermarkPolicy watermark yang dipasang server di setiap hasil. Request tidak bisa mematikannya,
+// hanya preset (no_watermark) dan aturan per API key dari file policy.
+//
+// Header X-API-Key sendiri yang menjadi bukti: aturan key hanya berlaku bila SHA-256 dari nilai
+// header sama dengan hash di file policy. Key harus secret acak minimal minAPIKeyLength byte yang
+// hanya diberikan ke partner, file policy hanya menyimpan hash-nya.
+type WatermarkPolicy struct {
+	Default *imageutil.Watermark
+	keys    []watermarkKey
+}
+
+type watermarkKey struct {
+	hash      [sha256.Size]byte
+	watermark *imageutil.Watermark // nil = API key ini tanpa watermark
+}
+
+// minAPIKeyLength key yang lebih pendek tidak pernah dicocokkan, supaya tidak bisa ditebak
+const minAPIKeyLength = 16
+
+// LoadWatermarkPolicy membaca file policy JSON, gambar dan teks watermark dirender sekali di sini.
+// Aturan key ditulis dengan SHA-256 hex dari API key (printf %s "$KEY" | sha256sum).
+//
+//	{"default": {...}, "keys": {"<sha256 api key>": {"disabled": true}, "<sha256 api key>": {...}}}
+func LoadWatermarkPolicy(path string, textOptions imageutil.TextOptions) (*WatermarkPolicy, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	var cfg struct {
+		Default *WatermarkConfig           `json:"default"`
+		Keys    map[string]WatermarkConfig `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("watermark policy: %w", err)
+	}
+
+	policy := &WatermarkPolicy{keys: make([]watermarkKey, 0, len(cfg.Keys))}
+	if cfg.Default != nil {
+		if cfg.Default.Disabled {
+			return nil, errors.New("watermark policy: default cannot be disabled, remove it instead")
+		}
+		if policy.Default, err = cfg.Default.build(textOptions); err != nil {
+			return nil, fmt.Errorf("watermark policy: default: %w", err)
+		}
+	}
+	for key, c := range cfg.Keys {
+		var rule watermarkKey
+		if n, err := hex.Decode(rule.hash[:], []byte(key)); err != nil || n != sha256.Size || len(key) != 2*sha256.Size {
+			return nil, errors.New("watermark policy: keys must be the SHA-256 hex of the API key")
+		}
+		if !c.Disabled {
+			if rule.watermark, err = c.build(textOptions); err != nil {
+				return nil, fmt.Errorf("watermark policy: key rule: %w", err)
+			}
+		}
+		policy.keys = append(policy.keys, rule)
+	}
+
+	log.Infof("Watermark policy => %s (%d key rules)", path, len(policy.keys))
+	return policy, nil
+}
+
+func (c *WatermarkConfig) build(textOptions imageutil.TextOptions) (*imageutil.Watermark, error) {
+	w := &imageutil.Watermark{
+		Position: c.Position,
+		Margin:   valueOr(c.Margin, 0.02),
+		Scale:    valueOr(c.Scale, 0.2),
+		Opacity:  valueOr(c.Opacity, 0.6),
+	}
+
+	switch {
+	case c.Image != "" && c.Text != "":
+		return nil, errors.New("watermark needs either image or text, not both")
+	case c.Image != "":
+		f, err := os.Open(filepath.Clean(c.Image))
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if w.Image, _, err = image.Decode(f); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", c.Image, err)
+		}
+	case c.Text != "":
+		if c.Font == "" {
+			return nil, errors.New("text watermark needs a font")
+		}
+		color := c.Color
+		if color == "" {
+			color = "#ffffff"
+		}
+		img, err := imageutil.RenderTextWatermark(c.Text, c.Font, color, textOptions)
+		if err != nil {
+			return nil, err
+		}
+		w.Image = img
+	}
+
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func valueOr(v *float64, def float64) float64 {
+	if v == nil {
+		return def
+	}
+	return *v
+}
+
+// For watermark untuk satu request, nil = tanpa watermark. Preset dengan no_watermark menang,
+// lalu aturan API key yang hash-nya cocok, lalu default. Semua aturan dibandingkan dengan
+// waktu konstan supaya hash tidak bisa ditebak dari lama respons.
+func (wp *WatermarkPolicy) For(p *preset.Preset, apiKey string) *imageutil.Watermark {
+	if wp == nil || p.NoWatermark {
+		return nil
+	}
+	if len(apiKey) < minAPIKeyLength {
+		return wp.Default
+	}
+
+	sum := sha256.Sum256([]byte(apiKey))
+	var match *watermarkKey
+	for i := range wp.keys {
+		if subtle.ConstantTimeCompare(sum[:], wp.keys[i].hash[:]) == 1 {
+			match = &wp.keys[i]
+		}
+	}
+	if match != nil {
+		return match.watermark
+	}
+	return wp.Default
+}
@@ -0,0 +1,52 @@
+package meme
+
+import (
+	"MemeCraft/internal/preset"
+	"MemeCraft/internal/service/imageutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatermarkPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watermark.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"default": {"text": "MemeCraft", "font": "../../../assets/fonts/OpenSans-Bold.ttf", "position": "top-left"},
+		"keys": {
+			"3e43412ad308a4752c68371055b3a9523b089c30c5f902986f9e5ecfa3d38f99": {"disabled": true},
+			"c2f5064aa11580e58aae92199f523879610ed1aade7b2db0f080aeef70f05b25": {"text": "Brand", "font": "../../../assets/fonts/OpenSans-Bold.ttf", "scale": 0.1, "opacity": 1}
+		}
+	}`), 0o600))
+
+	policy, err := LoadWatermarkPolicy(path, imageutil.TextOptions{})
+	require.NoError(t, err)
+
+	p := &preset.Preset{}
+	def := policy.For(p, "")
+	require.NotNil(t, def)
+	assert.Equal(t, imageutil.WatermarkTopLeft, def.Position)
+	assert.Equal(t, 0.2, def.Scale)
+	assert.Equal(t, 0.6, def.Opacity)
+
+	assert.Same(t, def, policy.For(p, "unknown-secret-key-0003"))
+	assert.Nil(t, policy.For(p, "partner-secret-key-0001"))
+	assert.Equal(t, 0.1, policy.For(p, "brand-secret-key-0002").Scale)
+	assert.Nil(t, policy.For(&preset.Preset{NoWatermark: true}, "brand-secret-key-0002"))
+	// nama key atau hash-nya sendiri bukan kredensial
+	assert.Same(t, def, policy.For(p, "partner"))
+	assert.Same(t, def, policy.For(p, "3e43412ad308a4752c68371055b3a9523b089c30c5f902986f9e5ecfa3d38f99"))
+
+	var none *WatermarkPolicy
+	assert.Nil(t, none.For(p, ""))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"default": {"text": "x"}}`), 0o600))
+	_, err = LoadWatermarkPolicy(path, imageutil.TextOptions{})
+	assert.EqualError(t, err, "watermark policy: default: text watermark needs a font")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys": {"partner": {"disabled": true}}}`), 0o600))
+	_, err = LoadWatermarkPolicy(path, imageutil.TextOptions{})
+	assert.EqualError(t, err, "watermark policy: keys must be the SHA-256 hex of the API key")
+}
@@ -0,0 +1,193 @@
+package provenance
+
+import (
+	"crypto/hmac"
+	"encoding/binary"
+	"encoding/hex"
+	"image"
+	"image/color"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"golang.org/x/image/draw"
+)
+
+// Watermark tak terlihat: payload 128 bit disebar ke blok 8x8 luminance. Setiap blok membawa
+// satu bit lewat tanda satu koefisien DCT frekuensi menengah (dipilih acak dari key), sehingga
+// tetap terbaca setelah gambar dikompres ulang selama ukurannya tidak berubah.
+const (
+	payloadBytes    = 16
+	payloadBits     = payloadBytes * 8
+	blockSize       = 8
+	minBlocksPerBit = 16
+	// jarak minimal koefisien dari nol, di atas setengah langkah kuantisasi JPEG quality 75
+	markStrength = 8
+	// pergeseran koefisien terbesar per blok (pixel berubah paling banyak maxShift/4). Blok
+	// bertekstur kuat yang butuh lebih dibiarkan, bitnya tetap terbaca dari blok lain.
+	maxShift = 2 * markStrength
+)
+
+// koefisien (u, v) yang bisa dipakai, frekuensi rendah mudah terlihat dan frekuensi tinggi
+// hilang saat kompresi
+var markCoefficients = [][2]int{{1, 2}, {2, 1}, {2, 2}}
+
+var dctBasis = func() (basis [][blockSize][blockSize]float64) {
+	basis = make([][blockSize][blockSize]float64, len(markCoefficients))
+	for i, uv := range markCoefficients {
+		for y := 0; y < blockSize; y++ {
+			for x := 0; x < blockSize; x++ {
+				// DCT ortonormal seperti JPEG, u dan v > 0 sehingga faktornya 2/8
+				basis[i][y][x] = 0.25 * math.Cos(float64(2*x+1)*float64(uv[0])*math.Pi/16) * math.Cos(float64(2*y+1)*float64(uv[1])*math.Pi/16)
+			}
+		}
+	}
+	return basis
+}()
+
+// markBlock tempat satu bit payload
+type markBlock struct {
+	x, y  int // pojok kiri atas
+	bit   int
+	sign  float64
+	basis int
+}
+
+// layout membagi blok ke bit payload secara acak dari key dan ukuran gambar, nil bila gambar
+// terlalu kecil
+func (s *Service) layout(w, h int) []markBlock {
+	cols, rows := w/blockSize, h/blockSize
+	n := cols * rows
+	if n < payloadBits*minBlocksPerBit {
+		return nil
+	}
+
+	var size [8]byte
+	binary.BigEndian.PutUint32(size[:4], uint32(w))
+	binary.BigEndian.PutUint32(size[4:], uint32(h))
+	r := rand.New(rand.NewChaCha8([32]byte(s.sign([]byte("layout"), size[:]))))
+
+	blocks := make([]markBlock, n)
+	for i, b := range r.Perm(n) {
+		blocks[i] = markBlock{
+			x:     b % cols * blockSize,
+			y:     b / cols * blockSize,
+			bit:   i % payloadBits,
+			sign:  float64(r.IntN(2)*2 - 1),
+			basis: r.IntN(len(markCoefficients)),
+		}
+	}
+	return blocks
+}
+
+// payload render ID (8 byte), hash preset (2 byte) dan tanda tangan (6 byte)
+func (s *Service) payload(rec Record) ([payloadBytes]byte, bool) {
+	var p [payloadBytes]byte
+	id, err := hex.DecodeString(rec.RenderID)
+	if err != nil || len(id) != 8 {
+		return p, false
+	}
+	copy(p[:8], id)
+	hash := presetHash(rec.PresetID)
+	copy(p[8:10], hash[:])
+	copy(p[10:], s.sign([]byte("mark"), p[:10]))
+	return p, true
+}
+
+// Mark mengembalikan salinan img dengan watermark tak terlihat. Gambar yang terlalu kecil
+// dikembalikan tanpa watermark.
+func (s *Service) Mark(img image.Image, rec Record) *image.RGBA {
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(out, out.Rect, img, b.Min, draw.Src)
+
+	blocks := s.layout(b.Dx(), b.Dy())
+	payload, ok := s.payload(rec)
+	if blocks == nil || !ok {
+		return out
+	}
+
+	for _, blk := range blocks {
+		want := blk.sign
+		if payload[blk.bit/8]>>(7-blk.bit%8)&1 == 0 {
+			want = -want
+		}
+		c := coefficient(out, blk) * want
+		if c >= markStrength {
+			continue
+		}
+		// basis ortonormal, jadi menambah k*basis menggeser koefisien sebesar k. Nilai yang sama
+		// ditambahkan ke R, G dan B sehingga hanya luminance yang berubah.
+		shiftBlock(out, blk, min(markStrength-c, maxShift)*want)
+	}
+	return out
+}
+
+func (s *Service) extract(img image.Image, presetIDs []string) (Record, bool) {
+	b := img.Bounds()
+	blocks := s.layout(b.Dx(), b.Dy())
+	if blocks == nil {
+		return Record{}, false
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Rect, img, b.Min, draw.Src)
+
+	// setiap blok dibatasi supaya blok bertekstur kuat tidak menentukan bit sendirian
+	var sums [payloadBits]float64
+	for _, blk := range blocks {
+		sums[blk.bit] += math.Max(-markStrength, math.Min(markStrength, coefficient(rgba, blk)*blk.sign))
+	}
+	var p [payloadBytes]byte
+	for i, sum := range sums {
+		if sum > 0 {
+			p[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	if !hmac.Equal(p[10:], s.sign([]byte("mark"), p[:10])[:payloadBytes-10]) {
+		return Record{}, false
+	}
+
+	rec := Record{
+		RenderID:  hex.EncodeToString(p[:8]),
+		CreatedAt: time.Unix(int64(binary.BigEndian.Uint32(p[:4])), 0).UTC(),
+	}
+	for _, id := range presetIDs {
+		if h := presetHash(id); h[0] == p[8] && h[1] == p[9] {
+			rec.PresetID = id
+			break
+		}
+	}
+	return rec, true
+}
+
+func luminance(c color.RGBA) float64 {
+	return 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+}
+
+func coefficient(img *image.RGBA, blk markBlock) float64 {
+	basis := &dctBasis[blk.basis]
+	var sum float64
+	for y := 0; y < blockSize; y++ {
+		for x := 0; x < blockSize; x++ {
+			sum += luminance(img.RGBAAt(blk.x+x, blk.y+y)) * basis[y][x]
+		}
+	}
+	return sum
+}
+
+func shiftBlock(img *image.RGBA, blk markBlock, k float64) {
+	basis := &dctBasis[blk.basis]
+	for y := 0; y < blockSize; y++ {
+		for x := 0; x < blockSize; x++ {
+			d := k * basis[y][x]
+			c := img.RGBAAt(blk.x+x, blk.y+y)
+			// premultiplied, warna tidak boleh melebihi alpha
+			a := float64(c.A)
+			img.SetRGBA(blk.x+x, blk.y+y, color.RGBA{R: clamp(float64(c.R)+d, a), G: clamp(float64(c.G)+d, a), B: clamp(float64(c.B)+d, a), A: c.A})
+		}
+	}
+}
+
+func clamp(v, limit float64) uint8 {
+	return uint8(math.Max(0, math.Min(limit, math.Round(v))))
+}
@@ -0,0 +1,220 @@
+package provenance
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metadata disimpan sebagai teks ASCII di komentar file:
+//
+//	memecraft-provenance:v1:<render id>:<preset id>:<unix>:<hmac base64url>
+//
+// HMAC juga mencakup SHA-256 file tanpa komentar itu, sehingga metadata yang dipindah ke
+// gambar lain atau gambar yang pixelnya diubah tidak lolos verifikasi.
+const metadataPrefix = "memecraft-provenance:v1:"
+
+// maxGIFComment satu sub-block GIF, metadata yang lebih panjang tidak ditulis supaya tetap utuh
+const maxGIFComment = 255
+
+func (s *Service) metadata(rec Record, sum [sha256.Size]byte) string {
+	unix := strconv.FormatInt(rec.CreatedAt.Unix(), 10)
+	return metadataPrefix + rec.RenderID + ":" + rec.PresetID + ":" + unix + ":" +
+		base64.RawURLEncoding.EncodeToString(s.metadataSign(rec.RenderID, rec.PresetID, unix, sum))
+}
+
+func (s *Service) metadataSign(renderID, presetID, unix string, sum [sha256.Size]byte) []byte {
+	return s.sign([]byte("metadata"), []byte(renderID), []byte(presetID), []byte(unix), sum[:])
+}
+
+// SignJPEG menambahkan segmen komentar (COM) bertanda tangan tepat setelah SOI
+func (s *Service) SignJPEG(data []byte, rec Record) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0xff || data[1] != 0xd8 {
+		return nil, errors.New("not a JPEG")
+	}
+	meta := s.metadata(rec, sha256.Sum256(data))
+
+	out := make([]byte, 0, len(data)+len(meta)+4)
+	out = append(out, data[:2]...)
+	out = append(out, 0xff, 0xfe)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(meta)+2))
+	out = append(out, meta...)
+	return append(out, data[2:]...), nil
+}
+
+// SignGIF menambahkan comment extension bertanda tangan sebelum trailer
+func (s *Service) SignGIF(data []byte, rec Record) ([]byte, error) {
+	if len(data) < 7 || !bytes.HasPrefix(data, []byte("GIF")) || data[len(data)-1] != 0x3b {
+		return nil, errors.New("not a GIF")
+	}
+	meta := s.metadata(rec, sha256.Sum256(data))
+	if len(meta) > maxGIFComment {
+		return nil, errors.New("provenance metadata too long for a GIF comment")
+	}
+
+	out := make([]byte, 0, len(data)+len(meta)+4)
+	out = append(out, data[:len(data)-1]...)
+	out = append(out, 0x21, 0xfe, byte(len(meta)))
+	out = append(out, meta...)
+	return append(out, 0x00, 0x3b), nil
+}
+
+// comment satu segmen COM JPEG atau comment extension GIF, start:end mencakup marker dan
+// header blok sehingga file tanpa komentar adalah data[:start] + data[end:]
+type comment struct {
+	start, end int
+	text       []byte
+}
+
+// parseMetadata mencari komentar provenance di JPEG atau GIF dan memeriksa tanda tangannya
+// terhadap isi file tanpa komentar tersebut
+func (s *Service) parseMetadata(data []byte) (Record, bool) {
+	var comments []comment
+	switch {
+	case len(data) >= 2 && data[0] == 0xff && data[1] == 0xd8:
+		comments = jpegComments(data)
+	case bytes.HasPrefix(data, []byte("GIF")):
+		comments = gifComments(data)
+	}
+
+	for _, c := range comments {
+		meta, ok := bytes.CutPrefix(c.text, []byte(metadataPrefix))
+		if !ok {
+			continue
+		}
+		h := sha256.New()
+		h.Write(data[:c.start])
+		h.Write(data[c.end:])
+		if rec, ok := s.checkMetadata(string(meta), [sha256.Size]byte(h.Sum(nil))); ok {
+			return rec, true
+		}
+	}
+	return Record{}, false
+}
+
+// jpegComments mengembalikan segmen COM sebelum SOS, data entropy setelahnya tidak dibaca
+func jpegComments(data []byte) []comment {
+	var out []comment
+	pos := 2 // setelah SOI
+	for pos+4 <= len(data) && data[pos] == 0xff {
+		marker := data[pos+1]
+		switch {
+		case marker == 0xff: // fill byte
+			pos++
+			continue
+		case marker == 0xda || marker == 0xd9: // SOS, EOI
+			return out
+		case marker == 0x01 || marker >= 0xd0 && marker <= 0xd7: // TEM, RSTn tanpa panjang
+			pos += 2
+			continue
+		}
+		end := pos + 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+		if end < pos+4 || end > len(data) {
+			return out
+		}
+		if marker == 0xfe {
+			out = append(out, comment{start: pos, end: end, text: data[pos+4 : end]})
+		}
+		pos = end
+	}
+	return out
+}
+
+// gifComments mengembalikan comment extension di antara blok GIF sampai trailer
+func gifComments(data []byte) []comment {
+	if len(data) < 13 {
+		return nil
+	}
+	pos := 13 // signature + logical screen descriptor
+	if data[10]&0x80 != 0 {
+		pos += 3 << (data[10]&0x07 + 1) // global color table
+	}
+
+	var out []comment
+	for pos < len(data) {
+		switch data[pos] {
+		case 0x21: // extension
+			if pos+2 > len(data) {
+				return out
+			}
+			start, label := pos, data[pos+1]
+			var text []byte
+			var ok bool
+			if pos, text, ok = subBlocks(data, pos+2, label == 0xfe); !ok {
+				return out
+			}
+			if label == 0xfe {
+				out = append(out, comment{start: start, end: pos, text: text})
+			}
+		case 0x2c: // image descriptor
+			pos += 10
+			if pos > len(data) {
+				return out
+			}
+			if data[pos-1]&0x80 != 0 {
+				pos += 3 << (data[pos-1]&0x07 + 1) // local color table
+			}
+			var ok bool
+			if pos, _, ok = subBlocks(data, pos+1, false); !ok { // LZW minimum code size + data
+				return out
+			}
+		default: // trailer atau blok tidak dikenal
+			return out
+		}
+	}
+	return out
+}
+
+// subBlocks melewati sub-block mulai pos sampai terminator, isinya digabung bila collect
+func subBlocks(data []byte, pos int, collect bool) (int, []byte, bool) {
+	var text []byte
+	for pos < len(data) {
+		size := int(data[pos])
+		pos++
+		if size == 0 {
+			return pos, text, true
+		}
+		if pos+size > len(data) {
+			break
+		}
+		if collect {
+			text = append(text, data[pos:pos+size]...)
+		}
+		pos += size
+	}
+	return pos, nil, false
+}
+
+func (s *Service) checkMetadata(meta string, sum [sha256.Size]byte) (Record, bool) {
+	// preset ID boleh berisi ":" jadi dipotong dari kedua sisi
+	renderID, rest, ok := strings.Cut(meta, ":")
+	if !ok {
+		return Record{}, false
+	}
+	i := strings.LastIndexByte(rest, ':')
+	if i < 0 {
+		return Record{}, false
+	}
+	rest, encodedSig := rest[:i], rest[i+1:]
+	i = strings.LastIndexByte(rest, ':')
+	if i < 0 {
+		return Record{}, false
+	}
+	presetID, unix := rest[:i], rest[i+1:]
+
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil || !hmac.Equal(sig, s.metadataSign(renderID, presetID, unix, sum)) {
+		return Record{}, false
+	}
+	sec, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil {
+		return Record{}, false
+	}
+	return Record{RenderID: renderID, PresetID: presetID, CreatedAt: time.Unix(sec, 0).UTC()}, true
+}
@@ -0,0 +1,98 @@
+// Package provenance menandai hasil render supaya asalnya bisa dibuktikan: metadata bertanda
+// tangan di file (lengkap tetapi hilang bila gambar di-encode ulang) dan watermark tak terlihat
+// di pixel (bertahan setelah kompresi ulang JPEG, tetapi tidak setelah resize atau crop).
+package provenance
+
+import (
+	"MemeCraft/internal/service/imageutil"
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+const minKeyLength = 16
+
+// Service menandai dan memverifikasi gambar dengan key server
+type Service struct {
+	key []byte
+}
+
+func New(key string) (*Service, error) {
+	if len(key) < minKeyLength {
+		return nil, errors.New("provenance key must be at least 16 bytes")
+	}
+	return &Service{key: []byte(key)}, nil
+}
+
+// Record asal satu hasil render. RenderID 8 byte hex: detik unix (4 byte) lalu 4 byte acak,
+// sehingga waktu render tetap bisa dibaca dari watermark yang hanya membawa RenderID.
+type Record struct {
+	RenderID  string
+	PresetID  string
+	CreatedAt time.Time
+}
+
+func NewRecord(presetID string, now time.Time) Record {
+	var id [8]byte
+	binary.BigEndian.PutUint32(id[:4], uint32(now.Unix()))
+	_, _ = rand.Read(id[4:])
+	return Record{RenderID: hex.EncodeToString(id[:]), PresetID: presetID, CreatedAt: now.UTC().Truncate(time.Second)}
+}
+
+// Result hasil verifikasi, Method "metadata" atau "watermark"
+type Result struct {
+	Verified  bool       `json:"verified"`
+	Method    string     `json:"method,omitempty"`
+	RenderID  string     `json:"render_id,omitempty"`
+	PresetID  string     `json:"preset_id,omitempty"` // kosong bila preset sudah tidak ada
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Reason    string     `json:"reason,omitempty"` // kenapa tidak terverifikasi, bila sebabnya diketahui
+}
+
+// gifCommentOnly GIF tidak punya watermark di pixel, jadi tanpa comment tidak ada yang bisa diperiksa
+const gifCommentOnly = "GIF results carry only the metadata comment, which is missing or invalid"
+
+// Verify memeriksa metadata lebih dulu, lalu watermark di pixel (hanya JPEG). presetIDs dipakai untuk
+// mencari preset dari hash di watermark.
+func (s *Service) Verify(data []byte, presetIDs []string, limits imageutil.DecodeLimits) (*Result, error) {
+	if rec, ok := s.parseMetadata(data); ok {
+		return rec.result("metadata"), nil
+	}
+	if bytes.HasPrefix(data, []byte("GIF")) {
+		return &Result{Verified: false, Reason: gifCommentOnly}, nil
+	}
+
+	img, err := imageutil.Decode(data, limits)
+	if err != nil {
+		return nil, err
+	}
+	if rec, ok := s.extract(img, presetIDs); ok {
+		return rec.result("watermark"), nil
+	}
+	return &Result{Verified: false}, nil
+}
+
+func (r Record) result(method string) *Result {
+	created := r.CreatedAt
+	return &Result{Verified: true, Method: method, RenderID: r.RenderID, PresetID: r.PresetID, CreatedAt: &created}
+}
+
+func (s *Service) sign(parts ...[]byte) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	for _, p := range parts {
+		mac.Write(p)
+		mac.Write([]byte{0})
+	}
+	return mac.Sum(nil)
+}
+
+// presetHash 2 byte untuk preset ID di watermark, cukup untuk membedakan preset satu instance
+func presetHash(id string) [2]byte {
+	sum := sha256.Sum256([]byte(id))
+	return [2]byte{sum[0], sum[1]}
+}
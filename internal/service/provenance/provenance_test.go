@@ -0,0 +1,177 @@
+package provenance
+
+import (
+	"MemeCraft/internal/service/imageutil"
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKey = "0123456789abcdef-test-key"
+
+// testImage gradient dengan noise supaya mirip foto, bukan bidang rata
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			n := uint8((x*7 + y*13 + x*y) % 37)
+			img.SetRGBA(x, y, color.RGBA{R: uint8(x*255/w) ^ n, G: uint8(y*255/h) + n, B: uint8((x+y)%256) / 2, A: 255})
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image, quality int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}))
+	return buf.Bytes()
+}
+
+func TestVerify_Metadata(t *testing.T) {
+	s, err := New(testKey)
+	require.NoError(t, err)
+	rec := NewRecord("cnn-breaking-news-preset", time.Date(2024, 8, 5, 10, 0, 0, 0, time.UTC))
+
+	data, err := s.SignJPEG(encodeJPEG(t, testImage(64, 64), 75), rec)
+	require.NoError(t, err)
+	res, err := s.Verify(data, nil, imageutil.DecodeLimits{})
+	require.NoError(t, err)
+	assert.True(t, res.Verified)
+	assert.Equal(t, "metadata", res.Method)
+	assert.Equal(t, rec.RenderID, res.RenderID)
+	assert.Equal(t, "cnn-breaking-news-preset", res.PresetID)
+	assert.Equal(t, rec.CreatedAt, *res.CreatedAt)
+
+	var buf bytes.Buffer
+	require.NoError(t, gif.Encode(&buf, testImage(16, 16), nil))
+	data, err = s.SignGIF(buf.Bytes(), rec)
+	require.NoError(t, err)
+	_, err = gif.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	res, err = s.Verify(data, nil, imageutil.DecodeLimits{})
+	require.NoError(t, err)
+	assert.True(t, res.Verified)
+
+	// key lain tidak bisa memverifikasi, dan metadata yang diubah ditolak
+	other, err := New("another-server-key-0000")
+	require.NoError(t, err)
+	res, err = other.Verify(data, nil, imageutil.DecodeLimits{})
+	require.NoError(t, err)
+	assert.False(t, res.Verified)
+	assert.Equal(t, gifCommentOnly, res.Reason)
+
+	forged := bytes.Replace(data, []byte("cnn-breaking"), []byte("xyz-breaking"), 1)
+	res, err = s.Verify(forged, nil, imageutil.DecodeLimits{})
+	require.NoError(t, err)
+	assert.False(t, res.Verified)
+}
+
+func TestVerify_GIFWithoutComment(t *testing.T) {
+	s, err := New(testKey)
+	require.NoError(t, err)
+
+	// GIF tanpa comment (mis. di-encode ulang) tidak punya watermark pixel untuk diperiksa
+	var buf bytes.Buffer
+	require.NoError(t, gif.Encode(&buf, testImage(400, 400), nil))
+	res, err := s.Verify(buf.Bytes(), nil, imageutil.DecodeLimits{})
+	require.NoError(t, err)
+	assert.False(t, res.Verified)
+	assert.Equal(t, gifCommentOnly, res.Reason)
+
+	// JPEG yang tidak terverifikasi tidak diberi alasan
+	res, err = s.Verify(encodeJPEG(t, testImage(64, 64), 75), nil, imageutil.DecodeLimits{})
+	require.NoError(t, err)
+	assert.False(t, res.Verified)
+	assert.Empty(t, res.Reason)
+}
+
+func TestVerify_MetadataTransplant(t *testing.T) {
+	s, err := New(testKey)
+	require.NoError(t, err)
+	rec := NewRecord("cnn-breaking-news-preset", time.Date(2024, 8, 5, 10, 0, 0, 0, time.UTC))
+
+	// segmen COM dari JPEG bertanda tangan dipindah ke JPEG lain
+	signed, err := s.SignJPEG(encodeJPEG(t, testImage(64, 64), 75), rec)
+	require.NoError(t, err)
+	com := signed[2 : 4+int(signed[4])<<8|int(signed[5])]
+	other := encodeJPEG(t, testImage(64, 48), 75)
+	transplanted := append(append(append([]byte{}, other[:2]...), com...), other[2:]...)
+	res, err := s.Verify(transplanted, nil, imageutil.DecodeLimits{})
+	require.NoError(t, err)
+	assert.False(t, res.Verified)
+
+	// pixel diubah setelah ditandatangani
+	tampered := bytes.Clone(signed)
+	tampered[len(tampered)-10] ^= 0x01
+	res, err = s.Verify(tampered, nil, imageutil.DecodeLimits{})
+	require.NoError(t, err)
+	assert.False(t, res.Verified)
+
+	// metadata di luar segmen COM tidak dibaca
+	appended := append(encodeJPEG(t, testImage(64, 64), 75), com[4:]...)
+	res, err = s.Verify(appended, nil, imageutil.DecodeLimits{})
+	require.NoError(t, err)
+	assert.False(t, res.Verified)
+
+	// comment extension dari GIF bertanda tangan dipindah ke GIF lain
+	var a, b bytes.Buffer
+	require.NoError(t, gif.Encode(&a, testImage(16, 16), nil))
+	require.NoError(t, gif.Encode(&b, testImage(24, 16), nil))
+	signed, err = s.SignGIF(a.Bytes(), rec)
+	require.NoError(t, err)
+	ext := signed[a.Len()-1 : len(signed)-1]
+	transplanted = append(append(bytes.Clone(b.Bytes()[:b.Len()-1]), ext...), 0x3b)
+	_, err = gif.Decode(bytes.NewReader(transplanted))
+	require.NoError(t, err)
+	res, err = s.Verify(transplanted, nil, imageutil.DecodeLimits{})
+	require.NoError(t, err)
+	assert.False(t, res.Verified)
+}
+
+func TestVerify_Watermark(t *testing.T) {
+	s, err := New(testKey)
+	require.NoError(t, err)
+	rec := NewRecord("kompas-ig-preset", time.Date(2024, 8, 5, 10, 0, 0, 0, time.UTC))
+
+	src := testImage(480, 480)
+	marked := s.Mark(src, rec)
+
+	// perubahan pixel kecil
+	maxDiff := 0
+	for i := range src.Pix {
+		maxDiff = max(maxDiff, int(src.Pix[i])-int(marked.Pix[i]), int(marked.Pix[i])-int(src.Pix[i]))
+	}
+	assert.LessOrEqual(t, maxDiff, maxShift/4+1)
+
+	// metadata hilang karena di-encode ulang, watermark tetap terbaca
+	data, err := imageutil.StripMetadata(encodeJPEG(t, marked, 75), imageutil.DecodeLimits{})
+	require.NoError(t, err)
+	img, err := jpeg.Decode(bytes.NewReader(encodeJPEG(t, decodeJPEG(t, data), 70)))
+	require.NoError(t, err)
+
+	res, err := s.Verify(encodeJPEG(t, img, 90), []string{"cnn-breaking-news-preset", "kompas-ig-preset"}, imageutil.DecodeLimits{})
+	require.NoError(t, err)
+	assert.True(t, res.Verified)
+	assert.Equal(t, "watermark", res.Method)
+	assert.Equal(t, rec.RenderID, res.RenderID)
+	assert.Equal(t, "kompas-ig-preset", res.PresetID)
+	assert.Equal(t, rec.CreatedAt, *res.CreatedAt)
+
+	// gambar tanpa watermark
+	res, err = s.Verify(encodeJPEG(t, src, 75), nil, imageutil.DecodeLimits{})
+	require.NoError(t, err)
+	assert.False(t, res.Verified)
+}
+
+func decodeJPEG(t *testing.T, data []byte) image.Image {
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	return img
+}
@@ -4,9 +4,12 @@ import (
 	"MemeCraft/internal/adapter/http"
 	"MemeCraft/internal/adapter/storage"
 	"MemeCraft/internal/preset"
+	"MemeCraft/internal/service/imageutil"
 	"MemeCraft/internal/service/meme"
+	"MemeCraft/internal/service/provenance"
 	"fmt"
 	"log"
+	_ "time/tzdata" // timezone preset tetap bisa dimuat di image alpine tanpa zoneinfo
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/bytedance/sonic"
@@ -15,6 +18,22 @@ import (
 
 var (
 	port = kingpin.Flag("port", "http port").Short('p').Default("3000").String()
+
+	maxUploadDimension  = kingpin.Flag("max-upload-dimension", "max width/height of images sent to /upload").Default("8000").Int()
+	maxUploadPixels     = kingpin.Flag("max-upload-pixels", "max total pixels of images sent to /upload").Default("40000000").Int()
+	maxOverlayDimension = kingpin.Flag("max-overlay-dimension", "max width/height of overlay images").Default("6000").Int()
+	maxOverlayPixels    = kingpin.Flag("max-overlay-pixels", "max total pixels of overlay images").Default("25000000").Int()
+	maxGIFFrames        = kingpin.Flag("max-gif-frames", "max number of frames in animated GIFs").Default("100").Int()
+	maxGIFDuration      = kingpin.Flag("max-gif-duration", "max total duration of animated GIFs").Default("20s").Duration()
+	maxGIFPixels        = kingpin.Flag("max-gif-pixels", "max frames x width x height of animated GIFs, for both the input GIF and the rendered frames").Default("50000000").Int()
+
+	fallbackFonts = kingpin.Flag("fallback-font", "font tried when a glyph is missing from the text box fonts, repeatable").
+			Default("assets/fonts/NotoSansArabic-Regular.ttf", "assets/fonts/DejaVuSans.ttf", "assets/fonts/mplus-1p-regular.ttf").Strings()
+	emojiDir = kingpin.Flag("emoji-dir", "directory of colour emoji PNGs, empty to draw emoji with fonts").Default("assets/emoji").String()
+
+	watermarkPolicy = kingpin.Flag("watermark", "watermark policy JSON applied to every generated meme, empty to disable").String()
+	provenanceKey   = kingpin.Flag("provenance-key", "secret for signing generated memes and POST /verify, at least 16 bytes, empty to disable").
+			Envar("MEMECRAFT_PROVENANCE_KEY").String()
 )
 
 func main() {
@@ -27,13 +46,48 @@ func main() {
 	app := newFiberApp()
 	catboxMoeStorage := storage.NewCatboxMoeStorage() // catbox.moe
 	_ = storage.NewZeroXZeroSTStorage()               // 0x0.st
-	memeGenerator := meme.NewGenerator(presetRegistry, catboxMoeStorage)
-	handler := http.NewHandler(memeGenerator, catboxMoeStorage)
+	textOptions := imageutil.TextOptions{
+		FallbackFonts: *fallbackFonts,
+		EmojiDir:      *emojiDir,
+	}
+	var watermark *meme.WatermarkPolicy
+	if *watermarkPolicy != "" {
+		var err error
+		if watermark, err = meme.LoadWatermarkPolicy(*watermarkPolicy, textOptions); err != nil {
+			log.Fatal(err)
+		}
+	}
+	var signer *provenance.Service
+	if *provenanceKey != "" {
+		var err error
+		if signer, err = provenance.New(*provenanceKey); err != nil {
+			log.Fatal(err)
+		}
+	}
+	memeGenerator := meme.NewGenerator(presetRegistry, catboxMoeStorage, textOptions, watermark, signer)
+	handler := http.NewHandler(memeGenerator, catboxMoeStorage, http.Limits{
+		Upload: imageutil.DecodeLimits{
+			MaxWidth:  *maxUploadDimension,
+			MaxHeight: *maxUploadDimension,
+			MaxPixels: *maxUploadPixels,
+		},
+		Overlay: imageutil.DecodeLimits{
+			MaxWidth:  *maxOverlayDimension,
+			MaxHeight: *maxOverlayDimension,
+			MaxPixels: *maxOverlayPixels,
+		},
+		Animation: imageutil.AnimationLimits{
+			MaxFrames:      *maxGIFFrames,
+			MaxDuration:    *maxGIFDuration,
+			MaxTotalPixels: *maxGIFPixels,
+		},
+	})
 
 	app.Get("/presets", handler.GetAllPreset)
 	app.Get("/presets/:preset_id", handler.GetPresetById)
 	app.Post("/presets/:preset_id/memes", handler.GenerateMeme)
 	app.Post("/upload", handler.UploadFile)
+	app.Post("/verify", handler.Verify)
 
 	app.Static("/", "./public")
 